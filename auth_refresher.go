@@ -0,0 +1,188 @@
+package helheim_go
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAuthRefreshLeadTime   = 5 * time.Minute
+	defaultAuthRefreshJitter     = 1 * time.Minute
+	defaultAuthRefreshMinBackoff = 1 * time.Second
+	defaultAuthRefreshMaxBackoff = 1 * time.Minute
+)
+
+type AuthRefresherOption func(config *authRefresherConfig)
+
+type authRefresherConfig struct {
+	leadTime   time.Duration
+	jitter     time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// WithRefreshLeadTime sets how long before the re auth interval ends the refresh happens. Defaults to five minutes.
+func WithRefreshLeadTime(leadTime time.Duration) AuthRefresherOption {
+	return func(config *authRefresherConfig) {
+		config.leadTime = leadTime
+	}
+}
+
+// WithRefreshJitter refreshes up to the given duration earlier at random. Defaults to one minute.
+func WithRefreshJitter(jitter time.Duration) AuthRefresherOption {
+	return func(config *authRefresherConfig) {
+		config.jitter = jitter
+	}
+}
+
+// WithRefreshBackoff sets the exponential backoff bounds between failed refresh attempts.
+func WithRefreshBackoff(minBackoff time.Duration, maxBackoff time.Duration) AuthRefresherOption {
+	return func(config *authRefresherConfig) {
+		config.minBackoff = minBackoff
+		config.maxBackoff = maxBackoff
+	}
+}
+
+// validate rejects a lead time and jitter which reach back before the last authentication,
+// as the refresher would authenticate again right after every refresh.
+func (config *authRefresherConfig) validate(reAuthInterval time.Duration, validationErr *ValidationError) {
+	if config.leadTime < 0 {
+		validationErr.add("AuthRefresher.LeadTime", "must not be negative, got %s", config.leadTime)
+	}
+
+	if config.jitter < 0 {
+		validationErr.add("AuthRefresher.Jitter", "must not be negative, got %s", config.jitter)
+	}
+
+	if config.leadTime+config.jitter >= reAuthInterval {
+		validationErr.add("AuthRefresher.LeadTime", "lead time %s plus jitter %s must be shorter than the re auth interval %s", config.leadTime, config.jitter, reAuthInterval)
+	}
+
+	if config.minBackoff <= 0 {
+		validationErr.add("AuthRefresher.MinBackoff", "must be positive, got %s", config.minBackoff)
+	}
+
+	if config.maxBackoff < config.minBackoff {
+		validationErr.add("AuthRefresher.MaxBackoff", "must not be shorter than the min backoff %s, got %s", config.minBackoff, config.maxBackoff)
+	}
+}
+
+type authRefresher struct {
+	logger  Logger
+	helheim *helheim
+	config  *authRefresherConfig
+	hooks   ClientHooks
+	random  *rand.Rand
+	stopLck sync.Mutex
+	stopCh  chan struct{}
+}
+
+func newAuthRefresher(logger Logger, helheim *helheim, config *authRefresherConfig, hooks ClientHooks) *authRefresher {
+	return &authRefresher{
+		logger:  withComponent(logger, ComponentAuth),
+		helheim: helheim,
+		config:  config,
+		hooks:   hooks,
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (r *authRefresher) start() {
+	r.stopLck.Lock()
+	defer r.stopLck.Unlock()
+
+	if r.stopCh != nil {
+		return
+	}
+
+	r.stopCh = make(chan struct{})
+
+	go r.run(r.stopCh)
+
+	logFields(r.logger, LevelInfo, "started helheim auth refresher", F(FieldOperation, "auth_refresh"))
+}
+
+func (r *authRefresher) stop() {
+	r.stopLck.Lock()
+	defer r.stopLck.Unlock()
+
+	if r.stopCh == nil {
+		return
+	}
+
+	close(r.stopCh)
+	r.stopCh = nil
+
+	logFields(r.logger, LevelInfo, "stopped helheim auth refresher", F(FieldOperation, "auth_refresh"))
+}
+
+func (r *authRefresher) run(stop chan struct{}) {
+	attempt := 0
+
+	for {
+		wait := r.nextRefreshIn()
+
+		if attempt > 0 {
+			wait = exponentialBackoff(attempt, r.config.minBackoff, r.config.maxBackoff)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-r.helheim.clock.After(wait):
+		}
+
+		err := r.helheim.refreshAuth()
+
+		if err != nil {
+			attempt++
+			logFields(r.logger, LevelWarn, "failed to refresh helheim auth", F(FieldOperation, "auth_refresh"), F("attempt", attempt), F(FieldError, err))
+
+			if r.hooks.OnAuthFailed != nil {
+				r.hooks.OnAuthFailed(err, attempt)
+			}
+
+			continue
+		}
+
+		attempt = 0
+		logFields(r.logger, LevelDebug, "refreshed helheim auth", F(FieldOperation, "auth_refresh"))
+
+		if r.hooks.OnAuthRefreshed != nil {
+			r.hooks.OnAuthRefreshed(r.helheim.AuthStatus())
+		}
+	}
+}
+
+func (r *authRefresher) nextRefreshIn() time.Duration {
+	status := r.helheim.AuthStatus()
+
+	refreshAt := status.LastAuthAt.Add(r.helheim.reAuthInterval - r.config.leadTime)
+
+	if r.config.jitter > 0 {
+		refreshAt = refreshAt.Add(-time.Duration(r.random.Int63n(int64(r.config.jitter))))
+	}
+
+	wait := refreshAt.Sub(r.helheim.clock.Now())
+
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+func exponentialBackoff(attempt int, minBackoff time.Duration, maxBackoff time.Duration) time.Duration {
+	wait := minBackoff
+
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+
+	return wait
+}
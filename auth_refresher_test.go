@@ -0,0 +1,134 @@
+package helheim_go
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuthRefresherRefreshesAheadOfReAuthInterval(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+
+	h, err := newHelheim("api-key", &clientConfig{clock: clock, reAuthInterval: 30 * time.Minute}, NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create helheim: %v", err)
+	}
+
+	refreshed := make(chan AuthStatus, 1)
+	config := &authRefresherConfig{leadTime: 5 * time.Minute, minBackoff: time.Second, maxBackoff: time.Minute}
+
+	refresher := newAuthRefresher(NewNoopLogger(), h, config, ClientHooks{
+		OnAuthRefreshed: func(status AuthStatus) {
+			refreshed <- status
+		},
+	})
+	refresher.start()
+	defer refresher.stop()
+
+	for i := 0; i < 3; i++ {
+		wait := clock.fire()
+		if wait != 25*time.Minute {
+			t.Fatalf("refresh %d: expected to wait 25m, got %s", i, wait)
+		}
+
+		status := <-refreshed
+		if !status.Authenticated || !status.LastAuthAt.Equal(clock.Now()) {
+			t.Fatalf("refresh %d: expected authentication at %s, got %+v", i, clock.Now(), status)
+		}
+	}
+}
+
+func TestAuthRefresherRejectsLeadTimeBeyondReAuthInterval(t *testing.T) {
+	_, err := NewClientWithOptions("api-key", WithReAuthInterval(5*time.Minute), WithAuthRefresher(WithRefreshLeadTime(5*time.Minute), WithRefreshJitter(0)))
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+}
+
+func TestAuthRefresherRejectsCustomBackend(t *testing.T) {
+	_, err := NewClientWithOptions("api-key", WithBackend(newFakeHelheim()), WithAuthRefresher())
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "AuthRefresher" {
+		t.Fatalf("expected a ValidationError for AuthRefresher, got %v", err)
+	}
+}
+
+// setTestApiKey switches the api key of h, the helheim stub fails to authenticate "invalid-api-key".
+func setTestApiKey(h *helheim, apiKey string) {
+	h.authLck.Lock()
+	defer h.authLck.Unlock()
+
+	h.apiKey = apiKey
+}
+
+func TestAuthRefresherBacksOffAfterFailures(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+
+	h, err := newHelheim("api-key", &clientConfig{clock: clock, reAuthInterval: 30 * time.Minute}, NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create helheim: %v", err)
+	}
+
+	setTestApiKey(h, "invalid-api-key")
+
+	failed := make(chan int, 1)
+	refreshed := make(chan AuthStatus, 1)
+	config := &authRefresherConfig{leadTime: 5 * time.Minute, jitter: time.Minute, minBackoff: time.Second, maxBackoff: 4 * time.Second}
+
+	refresher := newAuthRefresher(NewNoopLogger(), h, config, ClientHooks{
+		OnAuthFailed: func(err error, attempt int) {
+			failed <- attempt
+		},
+		OnAuthRefreshed: func(status AuthStatus) {
+			refreshed <- status
+		},
+	})
+	refresher.start()
+	defer refresher.stop()
+
+	assertJitteredWait := func(wait time.Duration) {
+		t.Helper()
+
+		if wait <= 24*time.Minute || wait > 25*time.Minute {
+			t.Fatalf("expected to wait between 24m and 25m, got %s", wait)
+		}
+	}
+
+	assertJitteredWait(clock.fire())
+
+	if attempt := <-failed; attempt != 1 {
+		t.Fatalf("expected attempt 1, got %d", attempt)
+	}
+
+	for i, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		wait := clock.fire()
+		if wait != expected {
+			t.Fatalf("retry %d: expected to wait %s, got %s", i+1, expected, wait)
+		}
+
+		if attempt := <-failed; attempt != i+2 {
+			t.Fatalf("retry %d: expected attempt %d, got %d", i+1, i+2, attempt)
+		}
+	}
+
+	if status := h.AuthStatus(); status.ConsecutiveFailures != 5 {
+		t.Fatalf("expected 5 consecutive failures, got %d", status.ConsecutiveFailures)
+	}
+
+	setTestApiKey(h, "api-key")
+
+	if wait := clock.fire(); wait != 4*time.Second {
+		t.Fatalf("expected to wait the max backoff, got %s", wait)
+	}
+
+	if status := <-refreshed; status.ConsecutiveFailures != 0 || !status.LastAuthAt.Equal(clock.Now()) {
+		t.Fatalf("expected a reset authentication at %s, got %+v", clock.Now(), status)
+	}
+
+	assertJitteredWait(clock.fire())
+
+	<-refreshed
+}
@@ -0,0 +1,190 @@
+package helheim_go
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBalancePollInterval = 10 * time.Minute
+	defaultExpiryWarning       = 24 * time.Hour
+)
+
+type BalanceMonitorOption func(config *balanceMonitorConfig)
+
+type balanceMonitorConfig struct {
+	pollInterval        time.Duration
+	lowBalance          []int
+	expiryWarning       time.Duration
+	expiredLicenseGuard bool
+}
+
+// WithBalancePollInterval sets how often the balance is requested. Defaults to ten minutes.
+func WithBalancePollInterval(interval time.Duration) BalanceMonitorOption {
+	return func(config *balanceMonitorConfig) {
+		config.pollInterval = interval
+	}
+}
+
+// WithLowBalanceThresholds fires ClientHooks.OnLowBalance once for every threshold the balance drops to or below.
+func WithLowBalanceThresholds(thresholds ...int) BalanceMonitorOption {
+	return func(config *balanceMonitorConfig) {
+		config.lowBalance = thresholds
+	}
+}
+
+// WithExpiryWarning fires ClientHooks.OnLicenseExpiring when the license expires within the given duration. Defaults to 24 hours.
+func WithExpiryWarning(before time.Duration) BalanceMonitorOption {
+	return func(config *balanceMonitorConfig) {
+		config.expiryWarning = before
+	}
+}
+
+// WithExpiredLicenseGuard refuses new sessions and requests with a LicenseExpiredError once the license is expired.
+func WithExpiredLicenseGuard() BalanceMonitorOption {
+	return func(config *balanceMonitorConfig) {
+		config.expiredLicenseGuard = true
+	}
+}
+
+type balanceMonitor struct {
+	client          *client
+	config          *balanceMonitorConfig
+	stateLck        sync.Mutex
+	firedLow        map[int]bool
+	warnedExpiry    time.Time
+	notifiedExpired bool
+	stopLck         sync.Mutex
+	stopCh          chan struct{}
+}
+
+func newBalanceMonitor(client *client, config *balanceMonitorConfig) *balanceMonitor {
+	thresholds := append([]int{}, config.lowBalance...)
+	sort.Sort(sort.Reverse(sort.IntSlice(thresholds)))
+	config.lowBalance = thresholds
+
+	return &balanceMonitor{
+		client:   client,
+		config:   config,
+		firedLow: make(map[int]bool),
+	}
+}
+
+func (config *balanceMonitorConfig) validate(validationErr *ValidationError) {
+	if config.pollInterval <= 0 {
+		validationErr.add("BalanceMonitor.PollInterval", "must be positive, got %s", config.pollInterval)
+	}
+
+	if config.expiryWarning < 0 {
+		validationErr.add("BalanceMonitor.ExpiryWarning", "must not be negative, got %s", config.expiryWarning)
+	}
+}
+
+func (m *balanceMonitor) start() {
+	m.stopLck.Lock()
+	defer m.stopLck.Unlock()
+
+	if m.stopCh != nil {
+		return
+	}
+
+	m.stopCh = make(chan struct{})
+
+	go m.run(m.stopCh)
+
+	logFields(m.client.logger, LevelInfo, "started helheim balance monitor", F(FieldOperation, "balance_monitor"), F("interval", m.config.pollInterval))
+}
+
+func (m *balanceMonitor) stop() {
+	m.stopLck.Lock()
+	defer m.stopLck.Unlock()
+
+	if m.stopCh == nil {
+		return
+	}
+
+	close(m.stopCh)
+	m.stopCh = nil
+
+	logFields(m.client.logger, LevelInfo, "stopped helheim balance monitor", F(FieldOperation, "balance_monitor"))
+}
+
+func (m *balanceMonitor) run(stop chan struct{}) {
+	m.poll()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-m.client.clock().After(m.config.pollInterval):
+			m.poll()
+		}
+	}
+}
+
+func (m *balanceMonitor) poll() {
+	b, err := m.client.GetBalance()
+
+	if err != nil {
+		logFields(m.client.logger, LevelWarn, "failed to poll helheim balance", F(FieldOperation, "balance_monitor"), F(FieldError, err))
+		return
+	}
+
+	m.check(b)
+}
+
+func (m *balanceMonitor) check(b *BalanceResponse) {
+	hooks := m.client.config.hooks
+	balance := b.Response.Balance
+	expiry := time.Unix(int64(b.Response.Expiry), 0)
+
+	m.stateLck.Lock()
+	defer m.stateLck.Unlock()
+
+	for _, threshold := range m.config.lowBalance {
+		if balance > threshold {
+			m.firedLow[threshold] = false
+			continue
+		}
+
+		if m.firedLow[threshold] {
+			continue
+		}
+
+		m.firedLow[threshold] = true
+		logFields(m.client.logger, LevelWarn, "helheim balance dropped to or below threshold", F(FieldOperation, "balance_monitor"), F("balance", balance), F("threshold", threshold))
+
+		if hooks.OnLowBalance != nil {
+			hooks.OnLowBalance(balance, threshold)
+		}
+	}
+
+	if b.Response.IsExpired {
+		if !m.notifiedExpired {
+			m.notifiedExpired = true
+			logFields(m.client.logger, LevelError, "helheim license expired", F(FieldOperation, "balance_monitor"), F("expiry", expiry))
+
+			if hooks.OnLicenseExpired != nil {
+				hooks.OnLicenseExpired(expiry)
+			}
+		}
+
+		return
+	}
+
+	m.notifiedExpired = false
+
+	if b.Response.Expiry <= 0 || m.warnedExpiry.Equal(expiry) {
+		return
+	}
+
+	if expiry.Sub(m.client.clock().Now()) <= m.config.expiryWarning {
+		m.warnedExpiry = expiry
+		logFields(m.client.logger, LevelWarn, "helheim license expires soon", F(FieldOperation, "balance_monitor"), F("expiry", expiry))
+
+		if hooks.OnLicenseExpiring != nil {
+			hooks.OnLicenseExpiring(expiry)
+		}
+	}
+}
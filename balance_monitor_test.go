@@ -0,0 +1,119 @@
+package helheim_go
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func startTestBalanceMonitor(t *testing.T, clock *fakeClock, backend *fakeHelheim, hooks ClientHooks, options ...BalanceMonitorOption) *client {
+	t.Helper()
+
+	c := newTestClient(t, backend, WithClock(clock), WithHooks(hooks), WithBalanceMonitor(append([]BalanceMonitorOption{WithBalancePollInterval(time.Minute)}, options...)...))
+	t.Cleanup(c.balanceMonitor.stop)
+
+	// the monitor polls once right away
+	clock.waitForTimer()
+
+	return c
+}
+
+func pollBalance(clock *fakeClock) {
+	clock.fire()
+	clock.waitForTimer()
+}
+
+func TestBalanceMonitorFiresThresholdsOnceUntilRearmed(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	backend := newFakeHelheim()
+	backend.setBalance(100, time.Time{}, false)
+
+	var fired [][2]int
+	startTestBalanceMonitor(t, clock, backend, ClientHooks{
+		OnLowBalance: func(balance int, threshold int) {
+			fired = append(fired, [2]int{balance, threshold})
+		},
+	}, WithLowBalanceThresholds(10, 50))
+
+	for _, balance := range []int{40, 40, 5, 100, 40} {
+		backend.setBalance(balance, time.Time{}, false)
+		pollBalance(clock)
+	}
+
+	expected := [][2]int{{40, 50}, {5, 10}, {40, 50}}
+	if !reflect.DeepEqual(fired, expected) {
+		t.Fatalf("expected thresholds %v to fire, got %v", expected, fired)
+	}
+}
+
+func TestBalanceMonitorReportsExpiringAndExpiredLicense(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	backend := newFakeHelheim()
+	expiry := clock.Now().Add(48 * time.Hour)
+	backend.setBalance(100, expiry, false)
+
+	var expiring, expired []time.Time
+	startTestBalanceMonitor(t, clock, backend, ClientHooks{
+		OnLicenseExpiring: func(expiry time.Time) {
+			expiring = append(expiring, expiry)
+		},
+		OnLicenseExpired: func(expiry time.Time) {
+			expired = append(expired, expiry)
+		},
+	}, WithExpiryWarning(24*time.Hour))
+
+	if len(expiring) != 0 {
+		t.Fatalf("expected no warning two days ahead of the expiry, got %v", expiring)
+	}
+
+	expiry = clock.Now().Add(12 * time.Hour)
+	backend.setBalance(100, expiry, false)
+	pollBalance(clock)
+	pollBalance(clock)
+
+	if len(expiring) != 1 || !expiring[0].Equal(expiry) {
+		t.Fatalf("expected one warning for %s, got %v", expiry, expiring)
+	}
+
+	backend.setBalance(100, expiry, true)
+	pollBalance(clock)
+	pollBalance(clock)
+
+	if len(expired) != 1 || !expired[0].Equal(expiry) {
+		t.Fatalf("expected one expired notification for %s, got %v", expiry, expired)
+	}
+}
+
+func TestExpiredLicenseGuardRefusesWork(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	backend := newFakeHelheim()
+	expiry := clock.Now().Add(time.Hour)
+	backend.setBalance(100, expiry, false)
+
+	c := startTestBalanceMonitor(t, clock, backend, ClientHooks{}, WithExpiredLicenseGuard())
+
+	s, err := c.NewSession(CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("expected a valid license to allow sessions, got %v", err)
+	}
+
+	// the expiry passes between two polls
+	clock.advance(2 * time.Hour)
+
+	var licenseErr *LicenseExpiredError
+
+	_, err = c.NewSession(CreateSessionOptions{})
+	if !errors.As(err, &licenseErr) || !licenseErr.Expiry.Equal(expiry) {
+		t.Fatalf("NewSession: expected a LicenseExpiredError for %s, got %v", expiry, err)
+	}
+
+	_, err = s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+	if !errors.Is(err, ErrLicenseExpired) {
+		t.Fatalf("Request: expected ErrLicenseExpired, got %v", err)
+	}
+
+	if requests := backend.count("request"); requests != 0 {
+		t.Fatalf("expected no request to reach helheim, got %d", requests)
+	}
+}
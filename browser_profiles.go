@@ -0,0 +1,253 @@
+package helheim_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// BrowserProfile bundles BrowserOptions with the matching User-Agent, Accept headers, client hints and header order
+// of one browser version on one platform. There are no Safari profiles as helheim only accepts chrome and firefox as
+// browser. The iOS profiles cover Chrome and Firefox on iOS instead, which send the WebKit headers of Safari.
+type BrowserProfile struct {
+	Name    string
+	Version string
+	Browser BrowserOptions
+	Headers map[string]string
+	// HeaderOrder lists the header names in the order helheim sends them when the profile gets applied on session
+	// creation. Headers not listed follow in alphabetical order.
+	HeaderOrder []string
+}
+
+var chromeHeaderOrder = []string{
+	"sec-ch-ua", "sec-ch-ua-mobile", "sec-ch-ua-platform", "upgrade-insecure-requests", "user-agent", "accept",
+	"sec-fetch-site", "sec-fetch-mode", "sec-fetch-user", "sec-fetch-dest", "accept-encoding", "accept-language",
+}
+
+var firefoxHeaderOrder = []string{
+	"user-agent", "accept", "accept-language", "accept-encoding", "upgrade-insecure-requests",
+	"sec-fetch-dest", "sec-fetch-mode", "sec-fetch-site", "sec-fetch-user",
+}
+
+var webkitHeaderOrder = []string{
+	"accept", "user-agent", "accept-language", "accept-encoding",
+}
+
+// The profile functions return a new copy on every call, so changing one never affects another session.
+
+func ProfileChrome103Windows() BrowserProfile {
+	return chromeProfile("chrome_103_windows", PlatformWindows, "Windows", false,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/103.0.0.0 Safari/537.36")
+}
+
+func ProfileChrome103MacOS() BrowserProfile {
+	return chromeProfile("chrome_103_macos", PlatformDarwin, "macOS", false,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/103.0.0.0 Safari/537.36")
+}
+
+func ProfileChrome103Linux() BrowserProfile {
+	return chromeProfile("chrome_103_linux", PlatformLinux, "Linux", false,
+		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/103.0.0.0 Safari/537.36")
+}
+
+func ProfileChrome103Android() BrowserProfile {
+	return chromeProfile("chrome_103_android", PlatformAndroid, "Android", true,
+		"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/103.0.0.0 Mobile Safari/537.36")
+}
+
+func ProfileChrome103IOS() BrowserProfile {
+	return iosProfile("chrome_103_ios", BrowserChrome, "103",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/103.0.5060.63 Mobile/15E148 Safari/604.1")
+}
+
+func ProfileFirefox102Windows() BrowserProfile {
+	return firefoxProfile("firefox_102_windows", PlatformWindows, false,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:102.0) Gecko/20100101 Firefox/102.0")
+}
+
+func ProfileFirefox102MacOS() BrowserProfile {
+	return firefoxProfile("firefox_102_macos", PlatformDarwin, false,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:102.0) Gecko/20100101 Firefox/102.0")
+}
+
+func ProfileFirefox102Linux() BrowserProfile {
+	return firefoxProfile("firefox_102_linux", PlatformLinux, false,
+		"Mozilla/5.0 (X11; Linux x86_64; rv:102.0) Gecko/20100101 Firefox/102.0")
+}
+
+func ProfileFirefox102Android() BrowserProfile {
+	return firefoxProfile("firefox_102_android", PlatformAndroid, true,
+		"Mozilla/5.0 (Android 12; Mobile; rv:102.0) Gecko/102.0 Firefox/102.0")
+}
+
+func ProfileFirefox102IOS() BrowserProfile {
+	return iosProfile("firefox_102_ios", BrowserFirefox, "102",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) FxiOS/102.0 Mobile/15E148 Safari/605.1.15")
+}
+
+var browserProfiles = []func() BrowserProfile{
+	ProfileChrome103Windows, ProfileChrome103MacOS, ProfileChrome103Linux, ProfileChrome103Android, ProfileChrome103IOS,
+	ProfileFirefox102Windows, ProfileFirefox102MacOS, ProfileFirefox102Linux, ProfileFirefox102Android, ProfileFirefox102IOS,
+}
+
+// GetBrowserProfile looks up a built-in profile by its name, e.g. chrome_103_windows.
+func GetBrowserProfile(name string) (BrowserProfile, bool) {
+	for _, newProfile := range browserProfiles {
+		if profile := newProfile(); profile.Name == name {
+			return profile, true
+		}
+	}
+
+	return BrowserProfile{}, false
+}
+
+func BrowserProfiles() []BrowserProfile {
+	profiles := make([]BrowserProfile, 0, len(browserProfiles))
+
+	for _, newProfile := range browserProfiles {
+		profiles = append(profiles, newProfile())
+	}
+
+	return profiles
+}
+
+func (p BrowserProfile) UserAgent() string {
+	return p.Headers["User-Agent"]
+}
+
+// SessionHeaders returns a copy of the profile headers.
+func (p BrowserProfile) SessionHeaders() map[string]string {
+	headers := make(map[string]string, len(p.Headers))
+
+	for key, value := range p.Headers {
+		headers[key] = value
+	}
+
+	return headers
+}
+
+// orderedHeadersHelheim is implemented by backends which send the headers in the given order.
+type orderedHeadersHelheim interface {
+	setOrderedHeaders(sessionId int, headers map[string]string, order []string) (*SetHeadersResponse, error)
+}
+
+func setOrderedHeaders(helheim Helheim, sessionId int, headers map[string]string, order []string) (*SetHeadersResponse, error) {
+	if h, ok := helheim.(orderedHeadersHelheim); ok {
+		return h.setOrderedHeaders(sessionId, headers, order)
+	}
+
+	return helheim.SetHeaders(sessionId, headers)
+}
+
+func chromeProfile(name string, platform Platform, hintPlatform string, mobile bool, userAgent string) BrowserProfile {
+	mobileHint := "?0"
+	if mobile {
+		mobileHint = "?1"
+	}
+
+	return BrowserProfile{
+		Name:    name,
+		Version: "103",
+		Browser: BrowserOptions{Browser: BrowserChrome, Mobile: mobile, Platform: platform},
+		Headers: map[string]string{
+			"sec-ch-ua":                 `".Not/A)Brand";v="99", "Google Chrome";v="103", "Chromium";v="103"`,
+			"sec-ch-ua-mobile":          mobileHint,
+			"sec-ch-ua-platform":        `"` + hintPlatform + `"`,
+			"Upgrade-Insecure-Requests": "1",
+			"User-Agent":                userAgent,
+			"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9",
+			"Accept-Encoding":           "gzip, deflate, br",
+			"Accept-Language":           "en-US,en;q=0.9",
+		},
+		HeaderOrder: append([]string(nil), chromeHeaderOrder...),
+	}
+}
+
+func firefoxProfile(name string, platform Platform, mobile bool, userAgent string) BrowserProfile {
+	return BrowserProfile{
+		Name:    name,
+		Version: "102",
+		Browser: BrowserOptions{Browser: BrowserFirefox, Mobile: mobile, Platform: platform},
+		Headers: map[string]string{
+			"User-Agent":                userAgent,
+			"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+			"Accept-Language":           "en-US,en;q=0.5",
+			"Accept-Encoding":           "gzip, deflate, br",
+			"Upgrade-Insecure-Requests": "1",
+		},
+		HeaderOrder: append([]string(nil), firefoxHeaderOrder...),
+	}
+}
+
+// iosProfile builds a profile of a browser on iOS. Every iOS browser runs on WebKit and sends the headers of Safari without client hints.
+func iosProfile(name string, browser BrowserName, version string, userAgent string) BrowserProfile {
+	return BrowserProfile{
+		Name:    name,
+		Version: version,
+		Browser: BrowserOptions{Browser: browser, Mobile: true, Platform: PlatformIOS},
+		Headers: map[string]string{
+			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			"User-Agent":      userAgent,
+			"Accept-Language": "en-US,en;q=0.9",
+			"Accept-Encoding": "gzip, deflate, br",
+		},
+		HeaderOrder: append([]string(nil), webkitHeaderOrder...),
+	}
+}
+
+// marshalHeaders keeps the given header order. Headers not listed follow in alphabetical order.
+func marshalHeaders(headers map[string]string, order []string) ([]byte, error) {
+	if len(order) == 0 {
+		return json.Marshal(headers)
+	}
+
+	keys := make([]string, 0, len(headers))
+	used := make(map[string]bool, len(headers))
+
+	for _, name := range order {
+		for key := range headers {
+			if !used[key] && strings.EqualFold(key, name) {
+				keys = append(keys, key)
+				used[key] = true
+			}
+		}
+	}
+
+	var remaining []string
+	for key := range headers {
+		if !used[key] {
+			remaining = append(remaining, key)
+		}
+	}
+
+	sort.Strings(remaining)
+	keys = append(keys, remaining...)
+
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		keyJson, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		valueJson, err := json.Marshal(headers[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(keyJson)
+		buf.WriteByte(':')
+		buf.Write(valueJson)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+package helheim_go
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBrowserProfilesAreCopies(t *testing.T) {
+	profile, ok := GetBrowserProfile("chrome_103_windows")
+	if !ok {
+		t.Fatal("expected the chrome profile to exist")
+	}
+
+	profile.Headers["User-Agent"] = "changed"
+	profile.HeaderOrder[0] = "changed"
+
+	for _, other := range append(BrowserProfiles(), ProfileChrome103Windows()) {
+		if other.UserAgent() == "changed" || other.HeaderOrder[0] == "changed" {
+			t.Fatalf("expected profile %s to be unaffected by changes of another copy", other.Name)
+		}
+	}
+}
+
+func TestMarshalHeadersKeepsOrder(t *testing.T) {
+	headers := map[string]string{"Accept": "*/*", "X-Extra": "1", "User-Agent": "ua", "A-Extra": "2"}
+
+	data, err := marshalHeaders(headers, []string{"user-agent", "accept"})
+	if err != nil {
+		t.Fatalf("failed to marshal headers: %v", err)
+	}
+
+	expected := `{"User-Agent":"ua","Accept":"*/*","A-Extra":"2","X-Extra":"1"}`
+	if string(data) != expected {
+		t.Fatalf("expected %s, got %s", expected, data)
+	}
+}
+
+// orderedFakeHelheim records the header order a profile is applied with.
+type orderedFakeHelheim struct {
+	*fakeHelheim
+	order []string
+}
+
+func (f *orderedFakeHelheim) setOrderedHeaders(sessionId int, headers map[string]string, order []string) (*SetHeadersResponse, error) {
+	f.order = order
+
+	return f.SetHeaders(sessionId, headers)
+}
+
+func TestSessionProfileIsAppliedInHeaderOrder(t *testing.T) {
+	backend := &orderedFakeHelheim{fakeHelheim: newFakeHelheim()}
+
+	c, err := NewClientWithOptions("api-key", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	profile := ProfileFirefox102Linux()
+
+	s, err := c.NewSession(CreateSessionOptions{Profile: &profile})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	if !reflect.DeepEqual(backend.order, profile.HeaderOrder) {
+		t.Fatalf("expected header order %v, got %v", profile.HeaderOrder, backend.order)
+	}
+
+	if s.GetHeaders()["User-Agent"] != profile.UserAgent() {
+		t.Errorf("expected the session to take over the profile headers, got %v", s.GetHeaders())
+	}
+}
+
+func TestBrowserProfilesAreValid(t *testing.T) {
+	for _, profile := range BrowserProfiles() {
+		err := CreateSessionOptions{Profile: &profile}.Validate()
+		if err != nil {
+			t.Errorf("profile %s: expected valid session options, got %v", profile.Name, err)
+		}
+	}
+}
+
+func TestIOSProfilesUseWebKitHeaders(t *testing.T) {
+	profiles := map[string]string{
+		"chrome_103_ios":  "CriOS/103",
+		"firefox_102_ios": "FxiOS/102",
+	}
+
+	for name, token := range profiles {
+		profile, ok := GetBrowserProfile(name)
+		if !ok {
+			t.Fatalf("expected the %s profile to exist", name)
+		}
+
+		if profile.Browser.Platform != PlatformIOS || !profile.Browser.Mobile {
+			t.Errorf("%s: expected a mobile iOS browser, got %+v", name, profile.Browser)
+		}
+
+		if !strings.Contains(profile.UserAgent(), token) || !strings.Contains(profile.UserAgent(), "iPhone") {
+			t.Errorf("%s: expected an iPhone user agent with %s, got %s", name, token, profile.UserAgent())
+		}
+
+		if _, ok = profile.Headers["sec-ch-ua"]; ok {
+			t.Errorf("%s: expected no client hints, WebKit does not send them", name)
+		}
+	}
+}
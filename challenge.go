@@ -0,0 +1,139 @@
+package helheim_go
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type ChallengeType string
+
+const (
+	ChallengeNone              ChallengeType = "none"
+	ChallengeCloudflareJS      ChallengeType = "cloudflare_js"
+	ChallengeCloudflareManaged ChallengeType = "cloudflare_managed"
+	ChallengeCaptcha           ChallengeType = "captcha"
+	ChallengeKasada            ChallengeType = "kasada"
+	ChallengePerimeterX        ChallengeType = "perimeterx"
+	ChallengeDataDome          ChallengeType = "datadome"
+	ChallengeRateLimited       ChallengeType = "rate_limited"
+	ChallengeAccessDenied      ChallengeType = "access_denied"
+)
+
+var cloudflareManagedMarkers = []string{"challenges.cloudflare.com/turnstile", "cf-turnstile", "ctype: 'managed'", "ctype:\"managed\"", "cf_chl_managed"}
+
+var cloudflareCaptchaMarkers = []string{"cf-captcha-container", "cf_captcha_kind", "ctype: 'interactive'"}
+
+var cloudflareJSMarkers = []string{"jschl_vc", "jschl-answer", "cf-browser-verification", "_cf_chl_opt", "cf_chl_jschl", "just a moment..."}
+
+var captchaMarkers = []string{"g-recaptcha", "h-captcha", "hcaptcha.com", "recaptcha/api.js"}
+
+var kasadaMarkers = []string{"kpsdk", "ips.js?"}
+
+var perimeterXMarkers = []string{"px-captcha", "_pxappid", "captcha.px-cdn.net", "client.perimeterx.net", "\"blockscript\""}
+
+var dataDomeMarkers = []string{"geo.captcha-delivery.com", "ct.captcha-delivery.com", "interstitial.captcha-delivery.com"}
+
+type challengeContextKey struct{}
+
+// IsBlocked reports whether the response did not reach the actual content.
+func (t ChallengeType) IsBlocked() bool {
+	return t != ChallengeNone && t != ""
+}
+
+// Challenge classifies the response of the request. See ClassifyResponse.
+func (r *RequestResponse) Challenge() ChallengeType {
+	return ClassifyResponse(r.Response.StatusCode, r.Response.Headers, r.Response.Body)
+}
+
+// ResponseChallenge returns the challenge the HttpClient detected for the response. It is kept in the context of
+// resp.Request, responses from elsewhere report ChallengeNone.
+func ResponseChallenge(resp *http.Response) ChallengeType {
+	if resp == nil || resp.Request == nil {
+		return ChallengeNone
+	}
+
+	challenge, ok := resp.Request.Context().Value(challengeContextKey{}).(ChallengeType)
+	if !ok {
+		return ChallengeNone
+	}
+
+	return challenge
+}
+
+func withChallenge(req *http.Request, challenge ChallengeType) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), challengeContextKey{}, challenge))
+}
+
+// ClassifyResponse detects the anti bot challenge or block a response still contains based on the status code,
+// headers and well known body markers.
+func ClassifyResponse(statusCode int, headers map[string]string, body string) ChallengeType {
+	lowerBody := strings.ToLower(body)
+
+	kasada := headerValue(headers, "x-kpsdk-ct") != "" || headerValue(headers, "x-kpsdk-cd") != "" || containsAny(lowerBody, kasadaMarkers)
+
+	if kasada && statusCode >= http.StatusBadRequest {
+		return ChallengeKasada
+	}
+
+	perimeterX := headerValue(headers, "x-px-block") != "" || containsAny(lowerBody, perimeterXMarkers)
+
+	if perimeterX && statusCode >= http.StatusBadRequest {
+		return ChallengePerimeterX
+	}
+
+	dataDome := headerValue(headers, "x-datadome") != "" || headerValue(headers, "x-dd-b") != "" ||
+		strings.EqualFold(headerValue(headers, "server"), "datadome") || containsAny(lowerBody, dataDomeMarkers)
+
+	if dataDome && statusCode >= http.StatusBadRequest {
+		return ChallengeDataDome
+	}
+
+	cloudflare := strings.EqualFold(headerValue(headers, "server"), "cloudflare") || headerValue(headers, "cf-ray") != ""
+
+	if cloudflare && statusCode >= http.StatusBadRequest {
+		switch {
+		case strings.EqualFold(headerValue(headers, "cf-mitigated"), "challenge") || containsAny(lowerBody, cloudflareManagedMarkers):
+			return ChallengeCloudflareManaged
+		case containsAny(lowerBody, cloudflareCaptchaMarkers):
+			return ChallengeCaptcha
+		case containsAny(lowerBody, cloudflareJSMarkers):
+			return ChallengeCloudflareJS
+		case strings.Contains(lowerBody, "error code: 1015") || strings.Contains(lowerBody, "you are being rate limited"):
+			return ChallengeRateLimited
+		case strings.Contains(lowerBody, "error code: 1020") || strings.Contains(lowerBody, "error code: 1010") || strings.Contains(lowerBody, "access denied"):
+			return ChallengeAccessDenied
+		}
+	}
+
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ChallengeRateLimited
+	case statusCode >= http.StatusBadRequest && containsAny(lowerBody, captchaMarkers):
+		return ChallengeCaptcha
+	case statusCode == http.StatusForbidden:
+		return ChallengeAccessDenied
+	}
+
+	return ChallengeNone
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,119 @@
+package helheim_go
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestClassifyResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		fixture    string
+		statusCode int
+		headers    map[string]string
+		expected   ChallengeType
+	}{
+		{
+			name:       "cloudflare js challenge",
+			fixture:    "cloudflare_js.html",
+			statusCode: http.StatusServiceUnavailable,
+			headers:    map[string]string{"Server": "cloudflare", "CF-RAY": "7a1b2c3d4e5f6a7b-FRA"},
+			expected:   ChallengeCloudflareJS,
+		},
+		{
+			name:       "cloudflare managed challenge",
+			fixture:    "cloudflare_managed.html",
+			statusCode: http.StatusForbidden,
+			headers:    map[string]string{"Server": "cloudflare", "cf-mitigated": "challenge"},
+			expected:   ChallengeCloudflareManaged,
+		},
+		{
+			name:       "cloudflare firewall rule",
+			fixture:    "cloudflare_access_denied.html",
+			statusCode: http.StatusForbidden,
+			headers:    map[string]string{"Server": "cloudflare", "CF-RAY": "7a1b2c3d4e5f6a7b-FRA"},
+			expected:   ChallengeAccessDenied,
+		},
+		{
+			name:       "perimeterx block",
+			fixture:    "perimeterx.html",
+			statusCode: http.StatusForbidden,
+			headers:    map[string]string{"Content-Type": "text/html"},
+			expected:   ChallengePerimeterX,
+		},
+		{
+			name:       "datadome captcha",
+			fixture:    "datadome.html",
+			statusCode: http.StatusForbidden,
+			headers:    map[string]string{"Server": "DataDome", "X-DataDome": "protected", "X-DD-B": "1"},
+			expected:   ChallengeDataDome,
+		},
+		{
+			name:       "kasada ips",
+			fixture:    "kasada.html",
+			statusCode: http.StatusTooManyRequests,
+			headers:    map[string]string{"x-kpsdk-ct": "0aXxRfC1bU2mYt"},
+			expected:   ChallengeKasada,
+		},
+		{
+			name:       "page behind cloudflare",
+			fixture:    "ok.html",
+			statusCode: http.StatusOK,
+			headers:    map[string]string{"Server": "cloudflare", "CF-RAY": "7a1b2c3d4e5f6a7b-FRA"},
+			expected:   ChallengeNone,
+		},
+		{
+			name:       "challenge markers on a successful page",
+			fixture:    "datadome.html",
+			statusCode: http.StatusOK,
+			headers:    map[string]string{},
+			expected:   ChallengeNone,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body := string(readFixture(t, "challenges/"+test.fixture))
+
+			challenge := ClassifyResponse(test.statusCode, test.headers, body)
+			if challenge != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, challenge)
+			}
+		})
+	}
+}
+
+func TestHttpClientClassifiesDecodedBase64Responses(t *testing.T) {
+	body := readFixture(t, "challenges/datadome.html")
+
+	backend := newFakeHelheim()
+	backend.onRequest = func(sessionId int, options RequestOptions) (*RequestResponse, error) {
+		return &RequestResponse{Response: RequestResponseResponse{
+			StatusCode: http.StatusForbidden,
+			Headers:    map[string]string{"Content-Type": "text/html"},
+			Content:    base64.StdEncoding.EncodeToString(body),
+		}}, nil
+	}
+
+	httpClient := newTestHttpClient(t, backend, WithBase64Response())
+
+	resp, err := httpClient.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if challenge := ResponseChallenge(resp); challenge != ChallengeDataDome {
+		t.Errorf("expected the decoded body to be classified, got %q", challenge)
+	}
+
+	if len(resp.Header) != 1 {
+		t.Errorf("expected only the headers of the response, got %v", resp.Header)
+	}
+
+	decoded, err := ioutil.ReadAll(resp.Body)
+	if err != nil || string(decoded) != string(body) {
+		t.Errorf("expected the decoded body, got %q (%v)", decoded, err)
+	}
+}
@@ -1,35 +1,84 @@
 package helheim_go
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
 type Client interface {
 	NewSession(options CreateSessionOptions) (Session, error)
+	GetSession(sessionId int) (Session, error)
+	ListSessions() []SessionInfo
 	DeleteSession(sessionId int) error
 	GetBalance() (*BalanceResponse, error)
+	AuthStatus() AuthStatus
+	UsageReport() UsageReport
 	GetHelheim() Helheim
 	NewHttpClient(sessionOptions CreateSessionOptions, options ...HttpClientOption) (HttpClient, error)
 	SetLogger(logger Logger)
+	StartReaper(options ...ReaperOption) error
+	StopReaper()
+	Close(ctx context.Context) error
 }
 
 type client struct {
-	logger  Logger
-	helheim Helheim
+	logger         Logger
+	logSink        *swappableLogger
+	helheim        Helheim
+	config         *clientConfig
+	sessionsLck    sync.RWMutex
+	sessions       map[int]*session
+	closed         bool
+	licenseLck     sync.Mutex
+	license        *BalanceResponse
+	balanceStart   *int
+	usageLck       sync.Mutex
+	closedUsage    map[string]Usage
+	reaperLck      sync.Mutex
+	reaperStop     chan struct{}
+	authRefresher  *authRefresher
+	balanceMonitor *balanceMonitor
+}
+
+type clientContainerEntry struct {
+	instance       Client
+	discover       bool
+	withAutoReAuth bool
+	logger         Logger
 }
 
 var clientContainer = struct {
 	sync.Mutex
-	instance Client
-}{}
+	instances map[string]*clientContainerEntry
+}{
+	instances: make(map[string]*clientContainerEntry),
+}
 
+// ProvideClient returns the shared client for the given api key and creates it on first use.
+// Requesting an already provided api key with a different discover or auto re auth setting fails with ErrConflictingClientOptions.
+// The same applies to a non nil logger differing from the one the client got created with. Use SetLogger to change it afterwards.
 func ProvideClient(apiKey string, discover bool, withAutoReAuth bool, logger Logger) (Client, error) {
 	clientContainer.Lock()
 	defer clientContainer.Unlock()
 
-	if clientContainer.instance != nil {
-		return clientContainer.instance, nil
+	entry, ok := clientContainer.instances[apiKey]
+
+	if ok && !isClosedClient(entry.instance) {
+		if entry.discover != discover || entry.withAutoReAuth != withAutoReAuth {
+			return nil, fmt.Errorf("%w: client already provided with discover=%v and withAutoReAuth=%v", ErrConflictingClientOptions, entry.discover, entry.withAutoReAuth)
+		}
+
+		if logger != nil && !sameLogger(entry.logger, logger) {
+			return nil, fmt.Errorf("%w: client already provided with a different logger, use SetLogger to replace it", ErrConflictingClientOptions)
+		}
+
+		return entry.instance, nil
 	}
 
 	instance, err := NewClient(apiKey, discover, withAutoReAuth, logger)
@@ -38,80 +87,396 @@ func ProvideClient(apiKey string, discover bool, withAutoReAuth bool, logger Log
 		return nil, err
 	}
 
-	clientContainer.instance = instance
+	clientContainer.instances[apiKey] = &clientContainerEntry{
+		instance:       instance,
+		discover:       discover,
+		withAutoReAuth: withAutoReAuth,
+		logger:         logger,
+	}
+
+	return instance, nil
+}
+
+// ResetClient forgets the provided client for the given api key without closing it.
+func ResetClient(apiKey string) {
+	clientContainer.Lock()
+	defer clientContainer.Unlock()
+
+	delete(clientContainer.instances, apiKey)
+}
+
+// CloseClient closes the provided client for the given api key and forgets it.
+func CloseClient(ctx context.Context, apiKey string) error {
+	clientContainer.Lock()
+	entry, ok := clientContainer.instances[apiKey]
+	delete(clientContainer.instances, apiKey)
+	clientContainer.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return entry.instance.Close(ctx)
+}
+
+// sameLogger compares two loggers without panicking on loggers of an uncomparable type, which always count as different.
+func sameLogger(a Logger, b Logger) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return a == nil && b == nil
+	}
+
+	return a == b
+}
+
+func isClosedClient(instance Client) bool {
+	c, ok := instance.(*client)
 
-	return clientContainer.instance, nil
+	return ok && c.isClosed()
 }
 
 func NewClient(apiKey string, discover bool, withAutoReAuth bool, logger Logger) (Client, error) {
-	if logger == nil {
-		logger = NewNoopLogger()
+	options := []ClientOption{
+		WithLogger(logger),
+	}
+
+	if discover {
+		options = append(options, WithDiscover())
 	}
 
-	h, err := newHelheim(apiKey, discover, withAutoReAuth, logger)
+	if withAutoReAuth {
+		options = append(options, WithAutoReAuth())
+	}
 
+	return NewClientWithOptions(apiKey, options...)
+}
+
+func NewClientWithOptions(apiKey string, options ...ClientOption) (Client, error) {
+	config := &clientConfig{
+		reAuthInterval: authValidMinutes * time.Minute,
+	}
+
+	for _, opt := range options {
+		opt(config)
+	}
+
+	err := config.validate()
 	if err != nil {
-		logger.Error("failed to create helheim client: %w", err)
 		return nil, err
 	}
 
-	logger.Info("created new helheim client")
+	if config.metrics == nil {
+		config.metrics = NewNoopMetrics()
+	}
+
+	if config.tracerProvider == nil {
+		config.tracerProvider = otel.GetTracerProvider()
+	}
+
+	redactor := newRedactor(append([]RedactionOption{WithRedactedSecrets(apiKey)}, config.redaction...)...)
+	logSink := newSwappableLogger(config.logger)
+	logger := withComponent(newRedactingLogger(logSink, redactor), ComponentClient)
+
+	c := &client{
+		logger:      logger,
+		logSink:     logSink,
+		helheim:     config.backend,
+		config:      config,
+		sessionsLck: sync.RWMutex{},
+		sessions:    make(map[int]*session),
+		closedUsage: make(map[string]Usage),
+	}
+
+	if c.helheim == nil {
+		h, err := newHelheim(apiKey, config, logger)
+
+		if err != nil {
+			logFields(logger, LevelError, "failed to create helheim client", F(FieldError, err))
+			return nil, err
+		}
+
+		c.helheim = h
+
+		if config.authRefresher != nil {
+			c.authRefresher = newAuthRefresher(logger, h, config.authRefresher, config.hooks)
+			c.authRefresher.start()
+		}
+	}
+
+	c.helheim = newTracingHelheim(newMetricsHelheim(c.helheim, config.metrics), config.tracerProvider)
+
+	if config.balanceMonitor != nil {
+		c.balanceMonitor = newBalanceMonitor(c, config.balanceMonitor)
+		c.balanceMonitor.start()
+	}
+
+	logFields(logger, LevelInfo, "created new helheim client")
 
-	return &client{
-		logger:  logger,
-		helheim: h,
-	}, nil
+	return c, nil
 }
 
 func (c *client) NewHttpClient(sessionOptions CreateSessionOptions, options ...HttpClientOption) (HttpClient, error) {
-	s, err := c.NewSession(sessionOptions)
+	s, err := c.createSession(sessionOptions)
 
 	if err != nil {
-		c.logger.Error("failed to create default session for helheim http client: %w", err)
+		logFields(c.logger, LevelError, "failed to create default session for helheim http client", F(FieldOperation, "create_http_client"), F(FieldError, err))
 		return nil, err
 	}
 
-	return newHttpClient(c.logger, s, options...), nil
+	httpClient, err := newHttpClient(c.logger, c.config.metrics, c.config.tracerProvider.Tracer(tracerName), s, func() (ContextSession, error) {
+		return c.createSession(sessionOptions)
+	}, options...)
+
+	if err != nil {
+		_ = s.Delete()
+		return nil, err
+	}
+
+	return httpClient, nil
 }
 
 func (c *client) NewSession(options CreateSessionOptions) (Session, error) {
-	s, err := newSession(c.logger, c.helheim, options)
+	s, err := c.createSession(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (c *client) createSession(options CreateSessionOptions) (*session, error) {
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	err := c.checkLicense()
+	if err != nil {
+		return nil, err
+	}
+
+	if options.isEmpty() && c.config.defaultSessionOptions != nil {
+		label := options.Label
+		options = *c.config.defaultSessionOptions
+
+		if label != "" {
+			options.Label = label
+		}
+	}
+
+	err = options.Validate()
+	if err != nil {
+		logFields(c.logger, LevelError, "invalid session options", F(FieldOperation, "create_session"), F(FieldError, err))
+		return nil, err
+	}
+
+	if options.Profile != nil {
+		options.Browser = options.Profile.Browser
+	}
+
+	s, err := newSession(c.logger, c.clock(), c.helheim, options, c.config.rateLimiter, c.checkLicense, c.sessionClosed)
 
 	if err != nil {
-		c.logger.Error("failed to create session: %w", err)
+		logFields(c.logger, LevelError, "failed to create session", F(FieldOperation, "create_session"), F(FieldError, err))
 		return nil, err
 	}
 
-	c.logger.Info("created new session with id: %d", s.GetSessionId())
+	if c.config.proxyPool != nil {
+		s.useProxyPool(c.config.proxyPool, c.config.proxyBlockStatus)
+	}
+
+	if options.Profile != nil {
+		_, err = s.setProfileHeaders(*options.Profile)
+
+		if err != nil {
+			logFields(c.logger, LevelError, "failed to apply browser profile", F(FieldOperation, "create_session"), F(FieldSessionId, s.GetSessionId()), F(FieldError, err))
+			c.discardSession(s, SessionCloseReasonDeleted)
+
+			return nil, err
+		}
+	}
+
+	c.sessionsLck.Lock()
+
+	// the client might have been closed while the session got created
+	if c.closed {
+		c.sessionsLck.Unlock()
+		c.discardSession(s, SessionCloseReasonClientClosed)
+
+		return nil, ErrClientClosed
+	}
+
+	c.sessions[s.GetSessionId()] = s
+	liveSessions := len(c.sessions)
+	c.sessionsLck.Unlock()
+
+	c.config.metrics.SetLiveSessions(liveSessions)
+
+	logFields(c.logger, LevelInfo, "created new session", F(FieldOperation, "create_session"), F(FieldSessionId, s.GetSessionId()))
+
+	if c.config.hooks.OnSessionCreated != nil {
+		c.config.hooks.OnSessionCreated(s)
+	}
+
+	return s, nil
+}
+
+func (c *client) GetSession(sessionId int) (Session, error) {
+	c.sessionsLck.RLock()
+	defer c.sessionsLck.RUnlock()
+
+	s, ok := c.sessions[sessionId]
+
+	if !ok {
+		return nil, fmt.Errorf("session %d: %w", sessionId, ErrSessionNotFound)
+	}
 
 	return s, nil
 }
 
+func (c *client) ListSessions() []SessionInfo {
+	c.sessionsLck.RLock()
+	defer c.sessionsLck.RUnlock()
+
+	infos := make([]SessionInfo, 0, len(c.sessions))
+
+	for _, s := range c.sessions {
+		infos = append(infos, s.info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+
+	return infos
+}
+
 func (c *client) GetBalance() (*BalanceResponse, error) {
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+
 	b, err := c.helheim.GetBalance()
 
 	if err != nil {
-		c.logger.Error("failed to retrieve balance: %w", err)
+		logFields(c.logger, LevelError, "failed to retrieve balance", F(FieldOperation, "get_balance"), F(FieldError, err))
 		return nil, err
 	}
 
+	c.licenseLck.Lock()
+	c.license = b
+
+	if c.balanceStart == nil {
+		balance := b.Response.Balance
+		c.balanceStart = &balance
+	}
+
+	c.licenseLck.Unlock()
+
+	c.config.metrics.SetBalance(b.Response.Balance)
+
 	return b, nil
 }
 
+// AuthStatus returns the cached authentication state of helheim together with the license expiry of the last balance response.
+// It never calls helheim. The license fields stay empty until the balance was retrieved once, e.g. by GetBalance or the balance monitor.
+func (c *client) AuthStatus() AuthStatus {
+	status := authStatusOf(c.helheim)
+
+	c.licenseLck.Lock()
+	license := c.license
+	c.licenseLck.Unlock()
+
+	if license != nil {
+		status.LicenseExpired = license.Response.IsExpired
+		status.LicenseExpiry = time.Unix(int64(license.Response.Expiry), 0)
+	}
+
+	return status
+}
+
+// SetLogger replaces the logger of the client and everything created by it, including existing sessions and http clients.
 func (c *client) SetLogger(logger Logger) {
-	c.logger = logger
-	c.helheim.SetLogger(logger)
+	c.logSink.set(logger)
 }
 
 func (c *client) GetHelheim() Helheim {
 	return c.helheim
 }
 
+// UsageReport sums up the usage of all sessions created by the client. Closed sessions are only contained in the totals per label.
+func (c *client) UsageReport() UsageReport {
+	report := UsageReport{
+		GeneratedAt: c.clock().Now(),
+		Total:       Usage{Errors: make(map[ErrorClass]int64)},
+		ByLabel:     make(map[string]Usage),
+	}
+
+	c.usageLck.Lock()
+	for label, usage := range c.closedUsage {
+		report.ByLabel[label] = usage.copy()
+		report.Total.add(usage)
+	}
+	c.usageLck.Unlock()
+
+	c.sessionsLck.RLock()
+	for _, s := range c.sessions {
+		usage := s.GetUsage()
+
+		report.Sessions = append(report.Sessions, SessionUsage{
+			SessionId: s.GetSessionId(),
+			Label:     s.GetLabel(),
+			Usage:     usage,
+		})
+
+		labelUsage := report.ByLabel[s.GetLabel()]
+		labelUsage.add(usage)
+		report.ByLabel[s.GetLabel()] = labelUsage
+		report.Total.add(usage)
+	}
+	c.sessionsLck.RUnlock()
+
+	sort.Slice(report.Sessions, func(i, j int) bool {
+		return report.Sessions[i].SessionId < report.Sessions[j].SessionId
+	})
+
+	c.licenseLck.Lock()
+	if c.balanceStart != nil && c.license != nil {
+		start := *c.balanceStart
+		latest := c.license.Response.Balance
+
+		report.BalanceStart = &start
+		report.BalanceLatest = &latest
+		report.BalanceDelta = start - latest
+	}
+	c.licenseLck.Unlock()
+
+	return report
+}
+
 func (c *client) DeleteSession(sessionId int) error {
+	c.sessionsLck.RLock()
+	s, ok := c.sessions[sessionId]
+	closed := c.closed
+	c.sessionsLck.RUnlock()
+
+	if closed {
+		return ErrClientClosed
+	}
+
+	if ok {
+		err := s.close(SessionCloseReasonDeleted)
+
+		if err != nil {
+			logFields(c.logger, LevelError, "failed to delete session", F(FieldOperation, "delete_session"), F(FieldSessionId, sessionId), F(FieldError, err))
+			return err
+		}
+
+		return nil
+	}
+
 	resp, err := c.helheim.DeleteSession(sessionId)
 
 	if err != nil {
-		c.logger.Error("failed to delete session: %w", err)
+		logFields(c.logger, LevelError, "failed to delete session", F(FieldOperation, "delete_session"), F(FieldSessionId, sessionId), F(FieldError, err))
 		return err
 	}
 
@@ -121,3 +486,154 @@ func (c *client) DeleteSession(sessionId int) error {
 
 	return nil
 }
+
+// Close deletes all sessions still registered on the client concurrently. Afterwards the client refuses to create new sessions
+// and its sessions refuse any further work, even when deleting them failed or ctx expired.
+func (c *client) Close(ctx context.Context) error {
+	c.sessionsLck.Lock()
+
+	if c.closed {
+		c.sessionsLck.Unlock()
+		return nil
+	}
+
+	c.closed = true
+
+	sessions := make([]*session, 0, len(c.sessions))
+	for _, s := range c.sessions {
+		if s.markClosed(SessionCloseReasonClientClosed) {
+			sessions = append(sessions, s)
+		}
+	}
+
+	c.sessionsLck.Unlock()
+
+	c.StopReaper()
+
+	if c.authRefresher != nil {
+		c.authRefresher.stop()
+	}
+
+	if c.balanceMonitor != nil {
+		c.balanceMonitor.stop()
+	}
+
+	errs := make(chan error, len(sessions))
+	wg := sync.WaitGroup{}
+
+	for _, s := range sessions {
+		wg.Add(1)
+
+		go func(s *session) {
+			defer wg.Done()
+
+			err := s.release(SessionCloseReasonClientClosed)
+
+			if err != nil {
+				errs <- fmt.Errorf("failed to delete session %d: %w", s.GetSessionId(), err)
+			}
+		}(s)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logFields(c.logger, LevelError, "failed to delete all sessions before closing client", F(FieldOperation, "close_client"), F(FieldError, ctx.Err()))
+		return ctx.Err()
+	}
+
+	close(errs)
+
+	var firstErr error
+	for err := range errs {
+		logFields(c.logger, LevelError, "error while closing client", F(FieldOperation, "close_client"), F(FieldError, err))
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	logFields(c.logger, LevelInfo, "closed helheim client", F(FieldOperation, "close_client"))
+
+	return firstErr
+}
+
+// checkLicense fails with a LicenseExpiredError when the expired license guard of the balance monitor is enabled and the last known license is expired.
+func (c *client) checkLicense() error {
+	if c.balanceMonitor == nil || !c.balanceMonitor.config.expiredLicenseGuard {
+		return nil
+	}
+
+	c.licenseLck.Lock()
+	license := c.license
+	c.licenseLck.Unlock()
+
+	if license == nil {
+		return nil
+	}
+
+	expiry := time.Unix(int64(license.Response.Expiry), 0)
+
+	if license.Response.IsExpired || (license.Response.Expiry > 0 && c.clock().Now().After(expiry)) {
+		return &LicenseExpiredError{Expiry: expiry}
+	}
+
+	return nil
+}
+
+// discardSession deletes a session which never got registered on the client without firing any hooks.
+func (c *client) discardSession(s *session, reason SessionCloseReason) {
+	s.onClose = nil
+
+	if s.markClosed(reason) {
+		_ = s.release(reason)
+	}
+}
+
+func (c *client) sessionLogger() Logger {
+	return withComponent(c.logger, ComponentSession)
+}
+
+func (c *client) clock() Clock {
+	if c.config.clock == nil {
+		return NewRealClock()
+	}
+
+	return c.config.clock
+}
+
+func (c *client) isClosed() bool {
+	c.sessionsLck.RLock()
+	defer c.sessionsLck.RUnlock()
+
+	return c.closed
+}
+
+func (c *client) sessionClosed(info SessionInfo, reason SessionCloseReason) {
+	c.sessionsLck.Lock()
+	s, ok := c.sessions[info.SessionId]
+	delete(c.sessions, info.SessionId)
+	liveSessions := len(c.sessions)
+	c.sessionsLck.Unlock()
+
+	c.config.metrics.SetLiveSessions(liveSessions)
+
+	if ok {
+		c.usageLck.Lock()
+		usage := c.closedUsage[info.Label]
+		usage.add(s.GetUsage())
+		c.closedUsage[info.Label] = usage
+		c.usageLck.Unlock()
+	}
+
+	if c.config.hooks.OnSessionClosed != nil {
+		c.config.hooks.OnSessionClosed(info, reason)
+	}
+}
@@ -0,0 +1,187 @@
+package helheim_go
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ClientOption func(config *clientConfig)
+
+type clientConfig struct {
+	discover              bool
+	withAutoReAuth        bool
+	reAuthInterval        time.Duration
+	logger                Logger
+	backend               Helheim
+	defaultSessionOptions *CreateSessionOptions
+	hooks                 ClientHooks
+	clock                 Clock
+	authRefresher         *authRefresherConfig
+	balanceMonitor        *balanceMonitorConfig
+	metrics               Metrics
+	tracerProvider        trace.TracerProvider
+	redaction             []RedactionOption
+	rateLimiter           *RateLimiter
+	proxyPool             *ProxyPool
+	proxyBlockStatus      []int
+}
+
+type ClientHooks struct {
+	OnSessionCreated  func(session Session)
+	OnSessionClosed   func(info SessionInfo, reason SessionCloseReason)
+	OnAuthRefreshed   func(status AuthStatus)
+	OnAuthFailed      func(err error, attempt int)
+	OnLowBalance      func(balance int, threshold int)
+	OnLicenseExpiring func(expiry time.Time)
+	OnLicenseExpired  func(expiry time.Time)
+}
+
+func WithDiscover() ClientOption {
+	return func(config *clientConfig) {
+		config.discover = true
+	}
+}
+
+func WithAutoReAuth() ClientOption {
+	return func(config *clientConfig) {
+		config.withAutoReAuth = true
+	}
+}
+
+// WithReAuthInterval sets after which duration helheim gets authenticated again. Defaults to 30 minutes.
+func WithReAuthInterval(interval time.Duration) ClientOption {
+	return func(config *clientConfig) {
+		config.reAuthInterval = interval
+	}
+}
+
+func WithLogger(logger Logger) ClientOption {
+	return func(config *clientConfig) {
+		config.logger = logger
+	}
+}
+
+// WithBackend replaces the cffi based helheim backend, e.g. with a fake implementation in tests.
+func WithBackend(backend Helheim) ClientOption {
+	return func(config *clientConfig) {
+		config.backend = backend
+	}
+}
+
+// WithDefaultSessionOptions sets the options used when a session is created with empty CreateSessionOptions.
+func WithDefaultSessionOptions(options CreateSessionOptions) ClientOption {
+	return func(config *clientConfig) {
+		config.defaultSessionOptions = &options
+	}
+}
+
+func WithHooks(hooks ClientHooks) ClientOption {
+	return func(config *clientConfig) {
+		config.hooks = hooks
+	}
+}
+
+// WithClock replaces the time source of the background workers.
+func WithClock(clock Clock) ClientOption {
+	return func(config *clientConfig) {
+		config.clock = clock
+	}
+}
+
+// WithAuthRefresher re-authenticates helheim in the background ahead of the re auth interval instead of lazily on the next call.
+// A backend set with WithBackend authenticates on its own, so combining both fails with a ValidationError.
+func WithAuthRefresher(options ...AuthRefresherOption) ClientOption {
+	return func(config *clientConfig) {
+		refresherConfig := &authRefresherConfig{
+			leadTime:   defaultAuthRefreshLeadTime,
+			jitter:     defaultAuthRefreshJitter,
+			minBackoff: defaultAuthRefreshMinBackoff,
+			maxBackoff: defaultAuthRefreshMaxBackoff,
+		}
+
+		for _, opt := range options {
+			opt(refresherConfig)
+		}
+
+		config.authRefresher = refresherConfig
+	}
+}
+
+// WithBalanceMonitor polls the helheim balance in the background and reports low balance and license expiry via ClientHooks.
+func WithBalanceMonitor(options ...BalanceMonitorOption) ClientOption {
+	return func(config *clientConfig) {
+		monitorConfig := &balanceMonitorConfig{
+			pollInterval:  defaultBalancePollInterval,
+			expiryWarning: defaultExpiryWarning,
+		}
+
+		for _, opt := range options {
+			opt(monitorConfig)
+		}
+
+		config.balanceMonitor = monitorConfig
+	}
+}
+
+// WithMetrics reports request, backend, session, auth and balance measurements to the given metrics implementation.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(config *clientConfig) {
+		config.metrics = metrics
+	}
+}
+
+// WithTracerProvider records OpenTelemetry spans for every helheim operation with the given provider. Defaults to the global provider.
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(config *clientConfig) {
+		config.tracerProvider = provider
+	}
+}
+
+// WithRedaction configures how secrets are masked in the log output of the client. The api key is always masked.
+func WithRedaction(options ...RedactionOption) ClientOption {
+	return func(config *clientConfig) {
+		config.redaction = append(config.redaction, options...)
+	}
+}
+
+// WithRateLimiter throttles Session.Request of all sessions created by the client, including the HttpClient sessions.
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(config *clientConfig) {
+		config.rateLimiter = limiter
+	}
+}
+
+// WithSessionProxyPool gives every session created by the client a proxy from the pool with its first request.
+// The proxy sticks to the session until a request fails with a proxy error or one of the given block status codes,
+// then the next request takes another one. The block status codes default to 403, 407 and 429.
+// Session.SetProxy with an empty proxy takes the next proxy from the pool, any other proxy is kept as set.
+func WithSessionProxyPool(pool *ProxyPool, blockStatusCodes ...int) ClientOption {
+	return func(config *clientConfig) {
+		config.proxyPool = pool
+		config.proxyBlockStatus = blockStatusCodes
+	}
+}
+
+// validate fails with a ValidationError when a background worker got configured with intervals it can not run with.
+func (config *clientConfig) validate() error {
+	validationErr := &ValidationError{}
+
+	if config.reAuthInterval <= 0 {
+		validationErr.add("ReAuthInterval", "must be positive, got %s", config.reAuthInterval)
+	}
+
+	if config.authRefresher != nil {
+		config.authRefresher.validate(config.reAuthInterval, validationErr)
+
+		if config.backend != nil {
+			validationErr.add("AuthRefresher", "can not refresh the authentication of a custom backend")
+		}
+	}
+
+	if config.balanceMonitor != nil {
+		config.balanceMonitor.validate(validationErr)
+	}
+
+	return validationErr.errOrNil()
+}
@@ -0,0 +1,273 @@
+package helheim_go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, backend *fakeHelheim, options ...ClientOption) *client {
+	t.Helper()
+
+	c, err := NewClientWithOptions("api-key", append([]ClientOption{WithBackend(backend)}, options...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	return c.(*client)
+}
+
+func TestClientListsSessionsInCreationOrder(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	c := newTestClient(t, newFakeHelheim(), WithClock(clock))
+
+	var ids []int
+	for _, label := range []string{"c", "a", "b"} {
+		s, err := c.NewSession(CreateSessionOptions{Label: label})
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+
+		ids = append(ids, s.GetSessionId())
+		clock.advance(time.Second)
+	}
+
+	infos := c.ListSessions()
+	if len(infos) != len(ids) {
+		t.Fatalf("expected %d sessions, got %d", len(ids), len(infos))
+	}
+
+	for i, info := range infos {
+		if info.SessionId != ids[i] {
+			t.Fatalf("position %d: expected session %d, got %d", i, ids[i], info.SessionId)
+		}
+	}
+}
+
+func TestClientCloseDeletesEverySessionOnce(t *testing.T) {
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend)
+
+	var sessions []Session
+	for i := 0; i < 3; i++ {
+		s, err := c.NewSession(CreateSessionOptions{})
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	err := c.Close(context.Background())
+	if err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+
+	err = c.Close(context.Background())
+	if err != nil {
+		t.Fatalf("expected closing twice to succeed, got %v", err)
+	}
+
+	if deletes := backend.count("delete_session"); deletes != len(sessions) {
+		t.Fatalf("expected %d deletions, got %d", len(sessions), deletes)
+	}
+
+	if infos := c.ListSessions(); len(infos) != 0 {
+		t.Fatalf("expected no sessions after close, got %d", len(infos))
+	}
+
+	for _, s := range sessions {
+		_, err = s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+		if !errors.Is(err, ErrSessionClosed) {
+			t.Fatalf("session %d: expected ErrSessionClosed, got %v", s.GetSessionId(), err)
+		}
+	}
+
+	if requests := backend.count("request"); requests != 0 {
+		t.Fatalf("expected no request to reach helheim, got %d", requests)
+	}
+}
+
+func TestClosedClientRefusesWork(t *testing.T) {
+	c := newTestClient(t, newFakeHelheim())
+
+	err := c.Close(context.Background())
+	if err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+
+	_, err = c.NewSession(CreateSessionOptions{})
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("NewSession: expected ErrClientClosed, got %v", err)
+	}
+
+	_, err = c.GetBalance()
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("GetBalance: expected ErrClientClosed, got %v", err)
+	}
+
+	err = c.DeleteSession(1)
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("DeleteSession: expected ErrClientClosed, got %v", err)
+	}
+
+	err = c.StartReaper()
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("StartReaper: expected ErrClientClosed, got %v", err)
+	}
+}
+
+func TestClientCloseKeepsSessionsClosedWhenDeleteFails(t *testing.T) {
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend)
+
+	s, err := c.NewSession(CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	deleteErr := errors.New("delete failed")
+	backend.deleteErr = deleteErr
+
+	err = c.Close(context.Background())
+	if !errors.Is(err, deleteErr) {
+		t.Fatalf("expected the delete error, got %v", err)
+	}
+
+	if !s.IsClosed() {
+		t.Fatal("expected the session to stay closed")
+	}
+
+	_, err = s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+	if !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("expected ErrSessionClosed, got %v", err)
+	}
+}
+
+func TestClientCloseReturnsContextError(t *testing.T) {
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend)
+
+	s, err := c.NewSession(CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	backend.onDelete = func(sessionId int) {
+		<-unblock
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = c.Close(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if !s.IsClosed() {
+		t.Fatal("expected the session to be closed")
+	}
+
+	_, err = c.NewSession(CreateSessionOptions{})
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+}
+
+func provideTestClient(t *testing.T, apiKey string, discover bool, withAutoReAuth bool, logger Logger) Client {
+	t.Helper()
+
+	c, err := ProvideClient(apiKey, discover, withAutoReAuth, logger)
+	if err != nil {
+		t.Fatalf("failed to provide client: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ResetClient(apiKey)
+	})
+
+	return c
+}
+
+func TestProvideClientSharesClientsPerApiKey(t *testing.T) {
+	first := provideTestClient(t, "provide-key-1", false, false, nil)
+	second := provideTestClient(t, "provide-key-2", false, false, nil)
+
+	if first == second {
+		t.Fatal("expected two api keys to get two clients")
+	}
+
+	if again := provideTestClient(t, "provide-key-1", false, false, nil); again != first {
+		t.Fatal("expected the same api key to get the same client")
+	}
+}
+
+func TestProvideClientRejectsConflictingOptions(t *testing.T) {
+	logger := &capturingLogger{}
+	provideTestClient(t, "provide-conflict", false, false, logger)
+
+	cases := map[string]struct {
+		discover       bool
+		withAutoReAuth bool
+		logger         Logger
+	}{
+		"discover":       {discover: true},
+		"withAutoReAuth": {withAutoReAuth: true},
+		"logger":         {logger: &capturingLogger{}},
+	}
+
+	for name, tc := range cases {
+		_, err := ProvideClient("provide-conflict", tc.discover, tc.withAutoReAuth, tc.logger)
+		if !errors.Is(err, ErrConflictingClientOptions) {
+			t.Errorf("%s: expected ErrConflictingClientOptions, got %v", name, err)
+		}
+	}
+
+	if _, err := ProvideClient("provide-conflict", false, false, logger); err != nil {
+		t.Fatalf("expected the creation logger to be accepted, got %v", err)
+	}
+
+	if _, err := ProvideClient("provide-conflict", false, false, nil); err != nil {
+		t.Fatalf("expected a nil logger to be accepted, got %v", err)
+	}
+}
+
+func TestProvideClientReplacesClosedClient(t *testing.T) {
+	first := provideTestClient(t, "provide-closed", false, false, nil)
+
+	err := first.Close(context.Background())
+	if err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+
+	second := provideTestClient(t, "provide-closed", true, false, nil)
+	if second == first {
+		t.Fatal("expected the closed client to be replaced")
+	}
+}
+
+func TestCloseClientClosesAndForgetsClient(t *testing.T) {
+	first := provideTestClient(t, "provide-close", false, false, nil)
+
+	err := CloseClient(context.Background(), "provide-close")
+	if err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+
+	if !isClosedClient(first) {
+		t.Fatal("expected CloseClient to close the client")
+	}
+
+	if second := provideTestClient(t, "provide-close", false, false, nil); second == first {
+		t.Fatal("expected CloseClient to forget the client")
+	}
+
+	if err = CloseClient(context.Background(), "provide-unknown"); err != nil {
+		t.Fatalf("expected closing an unknown api key to succeed, got %v", err)
+	}
+}
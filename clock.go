@@ -0,0 +1,23 @@
+package helheim_go
+
+import "time"
+
+// Clock abstracts the time source of the background workers so that they can be driven deterministically.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func NewRealClock() Clock {
+	return &realClock{}
+}
+
+func (c realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
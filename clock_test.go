@@ -0,0 +1,62 @@
+package helheim_go
+
+import (
+	"sync"
+	"time"
+)
+
+type fakeTimer struct {
+	d  time.Duration
+	ch chan time.Time
+}
+
+// fakeClock hands every After call to the test, which decides when the timer fires.
+type fakeClock struct {
+	lck    sync.Mutex
+	now    time.Time
+	timers chan fakeTimer
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{
+		now:    now,
+		timers: make(chan fakeTimer, 16),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lck.Lock()
+	defer c.lck.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.timers <- fakeTimer{d: d, ch: ch}
+
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) time.Time {
+	c.lck.Lock()
+	defer c.lck.Unlock()
+
+	c.now = c.now.Add(d)
+
+	return c.now
+}
+
+// fire advances the clock by the duration of the next timer and fires it.
+func (c *fakeClock) fire() time.Duration {
+	timer := <-c.timers
+	timer.ch <- c.advance(timer.d)
+
+	return timer.d
+}
+
+// waitForTimer waits until a background loop asked for its next timer, i.e. finished its current run.
+func (c *fakeClock) waitForTimer() {
+	timer := <-c.timers
+	c.timers <- timer
+}
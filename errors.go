@@ -0,0 +1,130 @@
+package helheim_go
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrHelheimResponse = errors.New("helheim error")
+var ErrClientClosed = errors.New("helheim client already closed")
+var ErrSessionNotFound = errors.New("helheim session not found")
+var ErrSessionClosed = errors.New("helheim session closed")
+var ErrAuthBackoff = errors.New("helheim authentication backing off after failure")
+var ErrLicenseExpired = errors.New("helheim license expired")
+var ErrInvalidOptions = errors.New("invalid helheim options")
+var ErrConflictingClientOptions = errors.New("helheim client already provided with different options")
+var ErrInvalidProxy = errors.New("invalid helheim proxy")
+var ErrNoProxyAvailable = errors.New("helheim proxy pool has no proxy available")
+var ErrProxyFailed = errors.New("helheim proxy failed")
+
+// proxyFailureMarkers are the messages of the python requests and urllib3 errors raised for an unusable proxy.
+var proxyFailureMarkers = []string{
+	"proxyerror",
+	"cannot connect to proxy",
+	"tunnel connection failed",
+	"proxy authentication required",
+	"sockshttpconnectionpool",
+	"sockshttpsconnectionpool",
+}
+
+type SessionCloseReason string
+
+const (
+	SessionCloseReasonDeleted      SessionCloseReason = "deleted"
+	SessionCloseReasonIdle         SessionCloseReason = "idle"
+	SessionCloseReasonMaxUses      SessionCloseReason = "max_uses"
+	SessionCloseReasonClientClosed SessionCloseReason = "client_closed"
+)
+
+type SessionClosedError struct {
+	SessionId int
+	Reason    SessionCloseReason
+}
+
+func (e *SessionClosedError) Error() string {
+	return fmt.Sprintf("helheim session %d closed: %s", e.SessionId, e.Reason)
+}
+
+func (e *SessionClosedError) Is(target error) bool {
+	return target == ErrSessionClosed
+}
+
+// ProxyFailedError is returned for a helheim error response caused by the proxy of the session.
+type ProxyFailedError struct {
+	Message string
+}
+
+func (e *ProxyFailedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrProxyFailed, e.Message)
+}
+
+func (e *ProxyFailedError) Is(target error) bool {
+	return target == ErrProxyFailed || target == ErrHelheimResponse
+}
+
+// helheimResponseError classifies the error message of a helheim response.
+func helheimResponseError(msg string) error {
+	lower := strings.ToLower(msg)
+
+	for _, marker := range proxyFailureMarkers {
+		if strings.Contains(lower, marker) {
+			return &ProxyFailedError{Message: msg}
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrHelheimResponse, msg)
+}
+
+type LicenseExpiredError struct {
+	Expiry time.Time
+}
+
+func (e *LicenseExpiredError) Error() string {
+	return fmt.Sprintf("helheim license expired at %s", e.Expiry)
+}
+
+func (e *LicenseExpiredError) Is(target error) bool {
+	return target == ErrLicenseExpired
+}
+
+type InvalidFieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *InvalidFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError collects all field errors of one options struct.
+type ValidationError struct {
+	Fields []*InvalidFieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidOptions, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidOptions
+}
+
+func (e *ValidationError) add(field string, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, &InvalidFieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -25,75 +25,140 @@ import "C"
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 	"unsafe"
 )
 
-const authValidMinutes = 30
+const (
+	authValidMinutes    = 30
+	authRetryMinBackoff = 1 * time.Second
+	authRetryMaxBackoff = 1 * time.Minute
+)
 
 type Helheim interface {
 	Auth() (*AuthResponse, error)
 	GetBalance() (*BalanceResponse, error)
 	CreateSession(options CreateSessionOptions) (*SessionResponse, error)
 	DeleteSession(sessionId int) (*SessionDeleteResponse, error)
-	Debug(sessionId int, state int) (interface{}, error)
+	Debug(sessionId int, level DebugLevel) (*DebugResponse, error)
 	Request(sessionId int, options RequestOptions) (*RequestResponse, error)
 	Wokou(sessionId int, browser string) (*WokouResponse, error)
 	SetProxy(sessionId int, proxy string) (*SetProxyResponse, error)
 	SetHeaders(sessionId int, headers map[string]string) (*SetHeadersResponse, error)
 	SetCookie(sessionId int, cookie SessionCookie) (*ModifyCookiesResponse, error)
 	DelCookie(sessionId int, cookieName string) (*ModifyCookiesResponse, error)
-	SetKasada(sessionId int, options KasadaOptions) (interface{}, error)
-	SetKasadaHooks(sessionId int, options KasadaHooksOptions) (interface{}, error)
+	SetKasada(sessionId int, options KasadaOptions) (*SetKasadaResponse, error)
+	SetKasadaHooks(sessionId int, options KasadaHooksOptions) (*SetKasadaHooksResponse, error)
 	SetLogger(logger Logger)
 }
 
+// authStatusHelheim is implemented by backends which keep track of their authentication state.
+type authStatusHelheim interface {
+	AuthStatus() AuthStatus
+}
+
+// lazyAuthHelheim is implemented by backends which authenticate on demand at the start of an operation.
+type lazyAuthHelheim interface {
+	needsReAuth() bool
+	reAuth() error
+}
+
+// authStatusOf returns an empty AuthStatus for backends not tracking their authentication state.
+func authStatusOf(h Helheim) AuthStatus {
+	if statusHelheim, ok := h.(authStatusHelheim); ok {
+		return statusHelheim.AuthStatus()
+	}
+
+	return AuthStatus{}
+}
+
 type helheim struct {
-	logger         Logger
-	apiKey         string
-	authLck        sync.Mutex
-	lastAuth       *time.Time
-	discover       bool
-	withAutoReAuth bool
+	logger           Logger
+	metrics          Metrics
+	clock            Clock
+	apiKey           string
+	authLck          sync.Mutex
+	lastAuth         *time.Time
+	lastAuthResponse *AuthResponse
+	authStatus       AuthStatus
+	discover         bool
+	withAutoReAuth   bool
+	reAuthInterval   time.Duration
 }
 
-func newHelheim(apiKey string, discover bool, withAutoReAuth bool, logger Logger) (Helheim, error) {
+func newHelheim(apiKey string, config *clientConfig, logger Logger) (*helheim, error) {
 	if logger == nil {
 		logger = NewNoopLogger()
 	}
 
+	clock := config.clock
+
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	metrics := config.metrics
+
+	if metrics == nil {
+		metrics = NewNoopMetrics()
+	}
+
 	h := &helheim{
-		logger:         logger,
+		logger:         withComponent(logger, ComponentSession),
+		metrics:        metrics,
+		clock:          clock,
 		apiKey:         apiKey,
-		withAutoReAuth: withAutoReAuth,
-		discover:       discover,
+		withAutoReAuth: config.withAutoReAuth,
+		reAuthInterval: config.reAuthInterval,
+		discover:       config.discover,
 		authLck:        sync.Mutex{},
 	}
 
-	auth, err := h.Auth()
+	_, err := h.Auth()
 
 	if err != nil {
 		return nil, err
 	}
 
-	if auth.Response != "authenticated" {
-		return nil, fmt.Errorf("could not authenticate against helheim")
-	}
-
-	logger.Info("initiated helheim")
+	logFields(h.authLogger(), LevelInfo, "initiated helheim", F(FieldOperation, "auth"))
 
 	return h, nil
 }
 
+// Auth authenticates against helheim when the last successful authentication is older than the re auth interval.
+// Otherwise the cached response of the last successful authentication is returned.
+// After a failed attempt further attempts fail with ErrAuthBackoff until the backoff elapsed.
 func (h *helheim) Auth() (*AuthResponse, error) {
 	h.authLck.Lock()
 	defer h.authLck.Unlock()
 
 	if !h.needReAuth() {
-		return nil, nil
+		authResponse := *h.lastAuthResponse
+		return &authResponse, nil
+	}
+
+	now := h.clock.Now()
+
+	if h.authStatus.ConsecutiveFailures > 0 && now.Before(h.authStatus.NextRetryAt) {
+		return nil, fmt.Errorf("%w: next attempt in %s after: %s", ErrAuthBackoff, h.authStatus.NextRetryAt.Sub(now), h.authStatus.LastError)
 	}
 
+	return h.authenticate()
+}
+
+// refreshAuth authenticates regardless of the time passed since the last authentication.
+func (h *helheim) refreshAuth() error {
+	h.authLck.Lock()
+	defer h.authLck.Unlock()
+
+	_, err := h.authenticate()
+
+	return err
+}
+
+func (h *helheim) authenticate() (*AuthResponse, error) {
 	discover := 0
 
 	if h.discover {
@@ -103,19 +168,52 @@ func (h *helheim) Auth() (*AuthResponse, error) {
 	apiKey := C.CString(h.apiKey)
 
 	d := C.int(discover)
+	start := time.Now()
 	authResp := C.auth(apiKey, d)
 	jsonPayload := C.GoString(authResp)
 
 	C.free(unsafe.Pointer(apiKey))
 
-	h.logger.Debug("helheim response for Auth: %s", jsonPayload)
+	h.logResponse("Auth", 0, start, jsonPayload)
 	authResponse := AuthResponse{}
 	err := h.handleResponse(jsonPayload, &authResponse)
 
-	now := time.Now()
+	if err == nil && authResponse.Response != "authenticated" {
+		err = fmt.Errorf("could not authenticate against helheim: %s", authResponse.Response)
+	}
+
+	now := h.clock.Now()
+	h.authStatus.LastAttemptAt = now
+	h.authStatus.LastError = err
+
+	h.metrics.IncAuthRefresh(err == nil)
+
+	if err != nil {
+		h.authStatus.ConsecutiveFailures++
+		h.authStatus.NextRetryAt = now.Add(exponentialBackoff(h.authStatus.ConsecutiveFailures, authRetryMinBackoff, authRetryMaxBackoff))
+
+		logFields(h.authLogger(), LevelError, "failed to authenticate helheim", F(FieldOperation, "auth"), F("attempt", h.authStatus.ConsecutiveFailures), F(FieldError, err))
+
+		return nil, err
+	}
+
 	h.lastAuth = &now
+	h.lastAuthResponse = &authResponse
+	h.authStatus.LastAuthAt = now
+	h.authStatus.ConsecutiveFailures = 0
+	h.authStatus.NextRetryAt = time.Time{}
+
+	return &authResponse, nil
+}
+
+func (h *helheim) AuthStatus() AuthStatus {
+	h.authLck.Lock()
+	defer h.authLck.Unlock()
+
+	status := h.authStatus
+	status.Authenticated = h.lastAuth != nil && h.clock.Now().Sub(*h.lastAuth) < h.reAuthInterval
 
-	return &authResponse, err
+	return status
 }
 
 func (h *helheim) CreateSession(options CreateSessionOptions) (*SessionResponse, error) {
@@ -131,11 +229,12 @@ func (h *helheim) CreateSession(options CreateSessionOptions) (*SessionResponse,
 	}
 
 	opt := C.CString(string(optionsString))
+	start := time.Now()
 	jsonPayload := C.GoString(C.createSession(opt))
 
 	C.free(unsafe.Pointer(opt))
 
-	h.logger.Debug("helheim response for CreateSession: %s", jsonPayload)
+	h.logResponse("CreateSession", 0, start, jsonPayload)
 	sessionResponse := SessionResponse{}
 	err = h.handleResponse(jsonPayload, &sessionResponse)
 
@@ -148,9 +247,10 @@ func (h *helheim) GetBalance() (*BalanceResponse, error) {
 		return nil, err
 	}
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.getBalance())
 
-	h.logger.Debug("helheim response for GetBalance: %s", jsonPayload)
+	h.logResponse("GetBalance", 0, start, jsonPayload)
 	balanceResponse := BalanceResponse{}
 	err = h.handleResponse(jsonPayload, &balanceResponse)
 
@@ -165,9 +265,10 @@ func (h *helheim) DeleteSession(sessionId int) (*SessionDeleteResponse, error) {
 
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.deleteSession(sId))
 
-	h.logger.Debug("helheim response for DeleteSession: %s", jsonPayload)
+	h.logResponse("DeleteSession", sessionId, start, jsonPayload)
 	deleteResponse := SessionDeleteResponse{}
 	err = h.handleResponse(jsonPayload, &deleteResponse)
 
@@ -197,12 +298,13 @@ func (h *helheim) Request(sessionId int, options RequestOptions) (*RequestRespon
 	opt := C.CString(string(optionsString))
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.request(sId, opt))
 	C.free(unsafe.Pointer(opt))
 
 	requestResponse := RequestResponse{}
 
-	h.logger.Debug("helheim response for Request: %s", jsonPayload)
+	h.logResponse("Request", sessionId, start, jsonPayload)
 	err = h.handleResponse(jsonPayload, &requestResponse)
 
 	return &requestResponse, err
@@ -217,11 +319,12 @@ func (h *helheim) Wokou(sessionId int, browser string) (*WokouResponse, error) {
 	b := C.CString(browser)
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.wokou(sId, b))
 
 	C.free(unsafe.Pointer(b))
 
-	h.logger.Debug("helheim response for Wokou: %s", jsonPayload)
+	h.logResponse("Wokou", sessionId, start, jsonPayload)
 	wokouResponse := WokouResponse{}
 	err = h.handleResponse(jsonPayload, &wokouResponse)
 
@@ -237,11 +340,12 @@ func (h *helheim) SetProxy(sessionId int, proxy string) (*SetProxyResponse, erro
 	p := C.CString(proxy)
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.setProxy(sId, p))
 
 	C.free(unsafe.Pointer(p))
 
-	h.logger.Debug("helheim response for SetProxy: %s", jsonPayload)
+	h.logResponse("SetProxy", sessionId, start, jsonPayload)
 	setProxyResponse := SetProxyResponse{}
 	err = h.handleResponse(jsonPayload, &setProxyResponse)
 
@@ -249,12 +353,16 @@ func (h *helheim) SetProxy(sessionId int, proxy string) (*SetProxyResponse, erro
 }
 
 func (h *helheim) SetHeaders(sessionId int, headers map[string]string) (*SetHeadersResponse, error) {
+	return h.setOrderedHeaders(sessionId, headers, nil)
+}
+
+func (h *helheim) setOrderedHeaders(sessionId int, headers map[string]string, order []string) (*SetHeadersResponse, error) {
 	err := h.reAuth()
 	if err != nil {
 		return nil, err
 	}
 
-	headersString, err := json.Marshal(headers)
+	headersString, err := marshalHeaders(headers, order)
 
 	if err != nil {
 		return nil, err
@@ -263,11 +371,12 @@ func (h *helheim) SetHeaders(sessionId int, headers map[string]string) (*SetHead
 	headersParam := C.CString(string(headersString))
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.setHeaders(sId, headersParam))
 
 	C.free(unsafe.Pointer(headersParam))
 
-	h.logger.Debug("helheim response for SetHeaders: %s", jsonPayload)
+	h.logResponse("SetHeaders", sessionId, start, jsonPayload)
 	setHeadersResponse := SetHeadersResponse{}
 	err = h.handleResponse(jsonPayload, &setHeadersResponse)
 
@@ -289,9 +398,10 @@ func (h *helheim) SetCookie(sessionId int, cookie SessionCookie) (*ModifyCookies
 	c := C.CString(string(cookiePayload))
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.setCookie(sId, c))
 
-	h.logger.Debug("helheim response for SetCookie: %s", jsonPayload)
+	h.logResponse("SetCookie", sessionId, start, jsonPayload)
 	C.free(unsafe.Pointer(c))
 
 	setCookiesResponse := ModifyCookiesResponse{}
@@ -309,8 +419,9 @@ func (h *helheim) DelCookie(sessionId int, cookieName string) (*ModifyCookiesRes
 	c := C.CString(cookieName)
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.delCookie(sId, c))
-	h.logger.Debug("helheim response for DelCookie: %s", jsonPayload)
+	h.logResponse("DelCookie", sessionId, start, jsonPayload)
 
 	C.free(unsafe.Pointer(c))
 
@@ -320,22 +431,37 @@ func (h *helheim) DelCookie(sessionId int, cookieName string) (*ModifyCookiesRes
 	return &setCookiesResponse, err
 }
 
-func (h *helheim) Debug(sessionId int, state int) (interface{}, error) {
+func (h *helheim) Debug(sessionId int, level DebugLevel) (*DebugResponse, error) {
 	err := h.reAuth()
 	if err != nil {
 		return nil, err
 	}
 
 	sId := C.int(sessionId)
-	stateInt := C.int(state)
+	stateInt := C.int(level)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.debug(sId, stateInt))
-	h.logger.Debug("helheim response for Debug: %s", jsonPayload)
+	h.logResponse("Debug", sessionId, start, jsonPayload)
 
-	return jsonPayload, nil
+	return h.handleDebugResponse(jsonPayload)
 }
 
-func (h *helheim) SetKasada(sessionId int, options KasadaOptions) (interface{}, error) {
+// handleDebugResponse keeps accepting the plain text payload helheim answered debug calls with before.
+// Only JSON object payloads are checked for errors.
+func (h *helheim) handleDebugResponse(payload string) (*DebugResponse, error) {
+	debugResponse := DebugResponse{Raw: payload}
+
+	if !strings.HasPrefix(strings.TrimSpace(payload), "{") || !json.Valid([]byte(payload)) {
+		return &debugResponse, nil
+	}
+
+	err := h.handleResponse(payload, &debugResponse)
+
+	return &debugResponse, err
+}
+
+func (h *helheim) SetKasada(sessionId int, options KasadaOptions) (*SetKasadaResponse, error) {
 	err := h.reAuth()
 	if err != nil {
 		return nil, err
@@ -350,15 +476,19 @@ func (h *helheim) SetKasada(sessionId int, options KasadaOptions) (interface{},
 	opt := C.CString(string(optionsString))
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.setKasada(sId, opt))
-	h.logger.Debug("helheim response for SetKasada: %s", jsonPayload)
+	h.logResponse("SetKasada", sessionId, start, jsonPayload)
 
 	C.free(unsafe.Pointer(opt))
 
-	return jsonPayload, err
+	setKasadaResponse := SetKasadaResponse{}
+	err = h.handleResponse(jsonPayload, &setKasadaResponse)
+
+	return &setKasadaResponse, err
 }
 
-func (h *helheim) SetKasadaHooks(sessionId int, options KasadaHooksOptions) (interface{}, error) {
+func (h *helheim) SetKasadaHooks(sessionId int, options KasadaHooksOptions) (*SetKasadaHooksResponse, error) {
 	err := h.reAuth()
 	if err != nil {
 		return nil, err
@@ -373,16 +503,38 @@ func (h *helheim) SetKasadaHooks(sessionId int, options KasadaHooksOptions) (int
 	opt := C.CString(string(optionsString))
 	sId := C.int(sessionId)
 
+	start := time.Now()
 	jsonPayload := C.GoString(C.setKasadaHooks(sId, opt))
 
 	C.free(unsafe.Pointer(opt))
-	h.logger.Debug("helheim response for SetKasadaHooks: %s", jsonPayload)
+	h.logResponse("SetKasadaHooks", sessionId, start, jsonPayload)
+
+	setKasadaHooksResponse := SetKasadaHooksResponse{}
+	err = h.handleResponse(jsonPayload, &setKasadaHooksResponse)
 
-	return jsonPayload, err
+	return &setKasadaHooksResponse, err
 }
 
 func (h *helheim) SetLogger(logger Logger) {
-	h.logger = logger
+	h.logger = withComponent(logger, ComponentSession)
+}
+
+func (h *helheim) authLogger() Logger {
+	return withComponent(h.logger, ComponentAuth)
+}
+
+func (h *helheim) logResponse(operation string, sessionId int, start time.Time, jsonPayload string) {
+	fields := []Field{
+		F(FieldOperation, operation),
+		F(FieldDuration, time.Since(start)),
+		F(FieldPayload, jsonPayload),
+	}
+
+	if sessionId != 0 {
+		fields = append(fields, F(FieldSessionId, sessionId))
+	}
+
+	logFields(h.logger, LevelDebug, "helheim response", fields...)
 }
 
 func (h *helheim) handleResponse(jsonPayload string, ret interface{}) error {
@@ -391,14 +543,14 @@ func (h *helheim) handleResponse(jsonPayload string, ret interface{}) error {
 
 	if err != nil {
 		e := fmt.Errorf("could not unmarshall helheim response to error aware response: %w", err)
-		h.logger.Error("error while unmarshalling helheim response: %w", e)
+		logFields(h.logger, LevelError, "error while unmarshalling helheim response", F(FieldError, e))
 
 		return e
 	}
 
 	if errorResponse.Error {
-		e := fmt.Errorf("helheim error: %s", errorResponse.ErrorMsg)
-		h.logger.Error("error received in helheim response: %w", e)
+		e := helheimResponseError(errorResponse.ErrorMsg)
+		logFields(h.logger, LevelError, "error received in helheim response", F(FieldError, e))
 
 		return e
 	}
@@ -407,7 +559,7 @@ func (h *helheim) handleResponse(jsonPayload string, ret interface{}) error {
 
 	if err != nil {
 		e := fmt.Errorf("could not unmarshall helheim response to response type: %w", err)
-		h.logger.Error("error while unmarshalling helheim response: %w", e)
+		logFields(h.logger, LevelError, "error while unmarshalling helheim response", F(FieldError, e))
 		return e
 	}
 
@@ -419,33 +571,42 @@ func (h *helheim) needReAuth() bool {
 		return true
 	}
 
-	now := time.Now()
+	now := h.clock.Now()
 
 	minutes := now.Sub(*h.lastAuth).Minutes()
 
-	h.logger.Info("%.2f minutes since last authentication", minutes)
-
-	needsReAuth := minutes >= authValidMinutes
+	needsReAuth := now.Sub(*h.lastAuth) >= h.reAuthInterval
 
-	h.logger.Debug("%.2f minutes since last auth. need re auth: %v", minutes, needsReAuth)
+	logFields(h.authLogger(), LevelDebug, "checked helheim auth age", F(FieldOperation, "auth"), F("minutes_since_auth", fmt.Sprintf("%.2f", minutes)), F("need_re_auth", needsReAuth))
 
 	return needsReAuth
 }
 
-func (h *helheim) reAuth() error {
+// needsReAuth reports whether the next operation authenticates, i.e. auto re auth is enabled, the last authentication expired
+// and no backoff after a failed attempt is pending.
+func (h *helheim) needsReAuth() bool {
 	if !h.withAutoReAuth {
-		h.logger.Debug("auto re auth not enabled. skipping")
-		return nil
+		return false
 	}
 
-	if !h.needReAuth() {
+	h.authLck.Lock()
+	defer h.authLck.Unlock()
+
+	inBackoff := h.authStatus.ConsecutiveFailures > 0 && h.clock.Now().Before(h.authStatus.NextRetryAt)
+
+	return h.needReAuth() && !inBackoff
+}
+
+func (h *helheim) reAuth() error {
+	if !h.withAutoReAuth {
+		logFields(h.authLogger(), LevelDebug, "auto re auth not enabled. skipping", F(FieldOperation, "auth"))
 		return nil
 	}
 
 	_, err := h.Auth()
 
 	if err != nil {
-		h.logger.Error("failed to authenticate helheim: %w", err)
+		logFields(h.authLogger(), LevelError, "failed to authenticate helheim", F(FieldOperation, "auth"), F(FieldError, err))
 		return err
 	}
 
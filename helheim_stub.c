@@ -0,0 +1,35 @@
+//go:build helheim_stub
+
+// Stand-in for the helheim cffi library so that the package tests link without helheim installed.
+// Run the tests with: go test -tags helheim_stub ./...
+
+#include <string.h>
+
+static char okResponse[] = "{\"error\":false,\"errorMsg\":\"\"}";
+static char stubError[] = "{\"error\":true,\"errorMsg\":\"helheim stub\"}";
+
+// the api key "invalid-api-key" lets tests exercise failed authentications
+char *auth(char apiKey[], int discover) {
+	if (strcmp(apiKey, "invalid-api-key") == 0) {
+		return "{\"sessionID\":0,\"response\":\"invalid api key\"}";
+	}
+
+	return "{\"sessionID\":0,\"response\":\"authenticated\"}";
+}
+char *getBalance() { return "{\"error\":false,\"response\":{\"balance\":100,\"isExpired\":false,\"expiry\":0}}"; }
+char *bifrost(int sessionID, char libraryPath[]) { return okResponse; }
+char *wokou(int sessionID, char browser[]) { return okResponse; }
+
+char *createSession(char options[]) { return stubError; }
+char *deleteSession(int sessionID) { return okResponse; }
+char *debug(int sessionID, int state) { return okResponse; }
+
+char *request(int sessionID, char payload[]) { return stubError; }
+
+char *setProxy(int sessionID, char proxy[]) { return okResponse; }
+char *setHeaders(int sessionID, char headers[]) { return okResponse; }
+char *setKasada(int sessionID, char kasada[]) { return okResponse; }
+char *setKasadaHooks(int sessionID, char kasadaHooks[]) { return okResponse; }
+
+char *setCookie(int sessionID, char cookie[]) { return okResponse; }
+char *delCookie(int sessionID, char cookie[]) { return okResponse; }
@@ -0,0 +1,148 @@
+package helheim_go
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHandleDebugResponse(t *testing.T) {
+	h := &helheim{logger: NewNoopLogger()}
+
+	tests := []struct {
+		name    string
+		payload string
+		err     error
+	}{
+		{name: "plain text", payload: "debug mode enabled"},
+		{name: "empty", payload: ""},
+		{name: "json", payload: `{"error":false,"sessionID":3}`},
+		{name: "json error", payload: `{"error":true,"errorMsg":"unknown session"}`, err: ErrHelheimResponse},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := h.handleDebugResponse(test.payload)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+
+			if resp.Raw != test.payload {
+				t.Fatalf("expected the raw payload %q, got %q", test.payload, resp.Raw)
+			}
+		})
+	}
+}
+
+func newTestHelheim(t *testing.T, clock *fakeClock) *helheim {
+	t.Helper()
+
+	h, err := newHelheim("api-key", &clientConfig{clock: clock, reAuthInterval: 30 * time.Minute}, NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create helheim: %v", err)
+	}
+
+	return h
+}
+
+func TestHelheimAuthReturnsCachedResponse(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	h := newTestHelheim(t, clock)
+	authAt := clock.Now()
+
+	clock.advance(10 * time.Minute)
+
+	resp, err := h.Auth()
+	if err != nil {
+		t.Fatalf("expected the cached authentication, got %v", err)
+	}
+
+	if resp == nil || resp.Response != "authenticated" {
+		t.Fatalf("expected the cached response, got %+v", resp)
+	}
+
+	if status := h.AuthStatus(); !status.LastAttemptAt.Equal(authAt) || !status.Authenticated {
+		t.Fatalf("expected no further attempt after %s, got %+v", authAt, status)
+	}
+}
+
+func TestHelheimAuthBacksOffAfterFailures(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	h := newTestHelheim(t, clock)
+	authAt := clock.Now()
+
+	clock.advance(31 * time.Minute)
+	setTestApiKey(h, "invalid-api-key")
+
+	_, err := h.Auth()
+	if err == nil || errors.Is(err, ErrAuthBackoff) {
+		t.Fatalf("expected the authentication to fail, got %v", err)
+	}
+
+	status := h.AuthStatus()
+	if status.Authenticated || !status.LastAuthAt.Equal(authAt) || status.ConsecutiveFailures != 1 {
+		t.Fatalf("expected one failure keeping the last authentication at %s, got %+v", authAt, status)
+	}
+
+	if !status.NextRetryAt.Equal(clock.Now().Add(authRetryMinBackoff)) {
+		t.Fatalf("expected the next retry after %s, got %s", authRetryMinBackoff, status.NextRetryAt.Sub(clock.Now()))
+	}
+
+	clock.advance(authRetryMinBackoff / 2)
+
+	_, err = h.Auth()
+	if !errors.Is(err, ErrAuthBackoff) {
+		t.Fatalf("expected ErrAuthBackoff before the next retry, got %v", err)
+	}
+
+	if status = h.AuthStatus(); status.ConsecutiveFailures != 1 || !status.LastAttemptAt.Equal(clock.Now().Add(-authRetryMinBackoff/2)) {
+		t.Fatalf("expected no attempt during the backoff, got %+v", status)
+	}
+
+	clock.advance(authRetryMinBackoff / 2)
+
+	_, err = h.Auth()
+	if err == nil || errors.Is(err, ErrAuthBackoff) {
+		t.Fatalf("expected a failed retry once the backoff elapsed, got %v", err)
+	}
+
+	if status = h.AuthStatus(); status.ConsecutiveFailures != 2 || !status.NextRetryAt.Equal(clock.Now().Add(2*authRetryMinBackoff)) {
+		t.Fatalf("expected a doubled backoff after the second failure, got %+v", status)
+	}
+
+	setTestApiKey(h, "api-key")
+	clock.advance(2 * authRetryMinBackoff)
+
+	resp, err := h.Auth()
+	if err != nil || resp == nil {
+		t.Fatalf("expected the retry to authenticate, got %+v, %v", resp, err)
+	}
+
+	status = h.AuthStatus()
+	if !status.Authenticated || status.ConsecutiveFailures != 0 || !status.NextRetryAt.IsZero() || status.LastError != nil || !status.LastAuthAt.Equal(clock.Now()) {
+		t.Fatalf("expected the success to reset the failures, got %+v", status)
+	}
+}
+
+func TestClientAuthStatusReportsLicenseExpiry(t *testing.T) {
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend)
+
+	if status := c.AuthStatus(); !status.LicenseExpiry.IsZero() {
+		t.Fatalf("expected no license expiry before the balance got retrieved, got %s", status.LicenseExpiry)
+	}
+
+	expiry := time.Unix(1700000000, 0)
+	backend.setBalance(100, expiry, true)
+
+	_, err := c.GetBalance()
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+
+	status := c.AuthStatus()
+	if !status.LicenseExpiry.Equal(expiry) || !status.LicenseExpired {
+		t.Fatalf("expected the expired license of %s, got %+v", expiry, status)
+	}
+}
@@ -1,13 +1,17 @@
 package helheim_go
 
 import (
-	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type HttpClient interface {
@@ -22,41 +26,146 @@ type HttpClient interface {
 	DeleteCookie(cookieName string) error
 	GetSessionHeaders() map[string]string
 	GetSessionCookies() []SessionCookie
+	GetAppliedState() HttpClientAppliedState
+}
+
+// HttpClientAppliedState describes the settings already applied to the session of a HttpClient.
+type HttpClientAppliedState struct {
+	WokouBrowser string
+	ProxyUrl     string
+	Debug        DebugLevel
 }
 
 type httpClient struct {
-	closed  bool
-	logger  Logger
-	config  *httpClientConfig
-	session Session
+	closed     bool
+	logger     Logger
+	metrics    Metrics
+	tracer     trace.Tracer
+	config     *httpClientConfig
+	sessionLck sync.RWMutex
+	session    ContextSession
+	newSession func() (ContextSession, error)
+	blocked    int
+	appliedLck sync.Mutex
+	applied    HttpClientAppliedState
 }
 
-func newHttpClient(logger Logger, session Session, options ...HttpClientOption) HttpClient {
+func newHttpClient(logger Logger, metrics Metrics, tracer trace.Tracer, session ContextSession, newSession func() (ContextSession, error), options ...HttpClientOption) (HttpClient, error) {
 	config := &httpClientConfig{}
 	for _, opt := range options {
 		opt(config)
 	}
 
-	return &httpClient{
-		closed:  false,
-		logger:  logger,
-		session: session,
-		config:  config,
+	if config.proxyUrl != "" {
+		proxyUrl, err := NormalizeProxy(config.proxyUrl)
+		if err != nil {
+			logFields(logger, LevelError, "invalid proxy for http client", F(FieldOperation, "create_http_client"), F(FieldError, err))
+			return nil, err
+		}
+
+		config.proxyUrl = proxyUrl
+	}
+
+	if metrics == nil {
+		metrics = NewNoopMetrics()
+	}
+
+	if tracer == nil {
+		tracer = trace.NewNoopTracerProvider().Tracer(tracerName)
+	}
+
+	c := &httpClient{
+		closed:     false,
+		logger:     withComponent(logger, ComponentHttp),
+		metrics:    metrics,
+		tracer:     tracer,
+		session:    session,
+		newSession: newSession,
+		config:     config,
+	}
+
+	c.attachProxyPool(session)
+
+	if config.debug {
+		_, err := session.Debug(DebugLevelOn)
+
+		if err != nil {
+			logFields(c.logger, LevelError, "failed to set debug on http client default session", F(FieldOperation, "debug"), F(FieldSessionId, session.GetSessionId()), F(FieldError, err))
+			return nil, err
+		}
+
+		c.applied.Debug = DebugLevelOn
 	}
+
+	return c, nil
 }
 
+// SetProxy is applied to the session with the next request. An empty proxy url removes the proxy from the session.
+// An invalid proxy url is logged and the previous proxy is kept.
 func (c *httpClient) SetProxy(proxyUrl string) {
+	if proxyUrl != "" {
+		normalized, err := NormalizeProxy(proxyUrl)
+		if err != nil {
+			logFields(c.logger, LevelError, "refusing invalid proxy for http client", F(FieldOperation, "set_proxy"), F(FieldSessionId, c.currentSession().GetSessionId()), F(FieldError, err))
+			return
+		}
+
+		proxyUrl = normalized
+	}
+
+	c.appliedLck.Lock()
+	defer c.appliedLck.Unlock()
+
 	c.config.proxyUrl = proxyUrl
 }
 
 func (c *httpClient) GetProxy() string {
+	c.appliedLck.Lock()
+	defer c.appliedLck.Unlock()
+
 	return c.config.proxyUrl
 }
 
+func (c *httpClient) currentSession() ContextSession {
+	c.sessionLck.RLock()
+	defer c.sessionLck.RUnlock()
+
+	return c.session
+}
+
+func (c *httpClient) GetAppliedState() HttpClientAppliedState {
+	c.appliedLck.Lock()
+	state := c.applied
+	c.appliedLck.Unlock()
+
+	// the proxy might have been taken from a pool or rotated by the session
+	state.ProxyUrl = c.currentSession().GetProxy()
+
+	return state
+}
+
+// proxyPoolSession is implemented by the sessions of the client.
+type proxyPoolSession interface {
+	useProxyPool(pool *ProxyPool, blockStatusCodes []int)
+	proxyState() sessionProxy
+	keepPoolProxy(ctx context.Context, proxy string) (*SetProxyResponse, error)
+}
+
+// attachProxyPool lets the session take its proxy from the pool of the HttpClient, replacing a pool of the client.
+func (c *httpClient) attachProxyPool(session ContextSession) {
+	if c.config.proxyPool == nil {
+		return
+	}
+
+	if s, ok := session.(proxyPoolSession); ok {
+		s.useProxyPool(c.config.proxyPool, c.config.proxyBlockStatus)
+	}
+}
+
 func (c *httpClient) CloseIdleConnections() error {
 	c.closed = true
 
-	return c.session.Delete()
+	return c.currentSession().Delete()
 }
 
 func (c *httpClient) Get(url string) (resp *http.Response, err error) {
@@ -87,45 +196,74 @@ func (c *httpClient) Post(url, contentType string, body io.Reader) (resp *http.R
 }
 
 func (c *httpClient) SetCookie(cookie SessionCookie) error {
-	_, err := c.session.SetCookie(cookie)
+	_, err := c.currentSession().SetCookie(cookie)
 
 	return err
 }
 
 func (c *httpClient) DeleteCookie(cookieName string) error {
-	_, err := c.session.DelCookie(cookieName)
+	_, err := c.currentSession().DelCookie(cookieName)
 
 	return err
 }
 
 func (c *httpClient) Do(req *http.Request) (*http.Response, error) {
-	if c.closed {
-		return nil, fmt.Errorf("session already closed manually. please create new client instance")
+	start := time.Now()
+
+	ctx, span := c.tracer.Start(req.Context(), "helheim.http_client.do", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attributeSessionId.Int(c.currentSession().GetSessionId()),
+		attributeHttpMethod.String(req.Method),
+		attributeHttpHost.String(req.URL.Hostname()),
+	))
+
+	resp, err := c.doWithRecovery(ctx, req)
+
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+		span.SetAttributes(attributeHttpStatus.Int(statusCode))
 	}
 
-	if c.config.debug {
-		_, err := c.session.Debug(1)
+	endSpan(span, err)
 
-		if err != nil {
-			c.logger.Error("failed to set debug on http client default session: %w", err)
-			return nil, err
-		}
+	duration := time.Since(start)
+	c.metrics.ObserveRequest(req.URL.Hostname(), req.Method, statusCode, duration)
+
+	fields := []Field{
+		F(FieldOperation, "http_request"),
+		F(FieldSessionId, c.currentSession().GetSessionId()),
+		F(FieldUrl, req.URL.String()),
+		F(FieldStatusCode, statusCode),
+		F(FieldDuration, duration),
 	}
 
-	if c.config.wokouBrowser != "" {
-		wokouResp, err := c.session.Wokou(c.config.wokouBrowser)
+	if err != nil {
+		fields = append(fields, F(FieldError, err))
+	}
 
-		if err != nil {
-			c.logger.Error("failed to set wokou on http client default session: %w", err)
-			return nil, err
-		}
+	logFields(c.logger, LevelDebug, "helheim http client request done", fields...)
 
-		if wokouResp.Error {
-			err = fmt.Errorf("received error response from helheim: %s", wokouResp.ErrorMsg)
-			c.logger.Error("failed to set wokou on http client default session: %w", err)
+	return resp, err
+}
 
-			return nil, err
-		}
+func (c *httpClient) logError(req *http.Request, msg string, err error) {
+	logFields(c.logger, LevelError, msg,
+		F(FieldOperation, "http_request"),
+		F(FieldSessionId, c.currentSession().GetSessionId()),
+		F(FieldUrl, req.URL.String()),
+		F(FieldError, err),
+	)
+}
+
+// do sends the request on the given session, which is the current session of the client when the request started.
+func (c *httpClient) do(ctx context.Context, req *http.Request, session ContextSession) (*http.Response, error) {
+	if c.closed {
+		return nil, fmt.Errorf("session already closed manually. please create new client instance")
+	}
+
+	err := c.applySessionSettings(ctx, req, session)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(req.Header) > 0 {
@@ -134,32 +272,16 @@ func (c *httpClient) Do(req *http.Request) (*http.Response, error) {
 			headerMap[key] = value[0] // TODO: find a better handling here
 		}
 
-		headerResp, err := c.session.SetHeaders(headerMap)
+		headerResp, err := session.SetHeadersContext(ctx, headerMap)
 
 		if err != nil {
-			c.logger.Error("failed to set header on http client default session: %w", err)
+			c.logError(req, "failed to set header on http client default session", err)
 			return nil, err
 		}
 
 		if headerResp.Error {
 			err = fmt.Errorf("received error response from helheim: %s", headerResp.ErrorMsg)
-			c.logger.Error("failed to set header on http client default session: %w", err)
-
-			return nil, err
-		}
-	}
-
-	if c.config.proxyUrl != "" {
-		proxyResp, err := c.session.SetProxy(c.config.proxyUrl)
-
-		if err != nil {
-			c.logger.Error("failed to set proxy on http client default session: %w", err)
-			return nil, err
-		}
-
-		if proxyResp.Error {
-			err = fmt.Errorf("received error response from helheim: %s", proxyResp.ErrorMsg)
-			c.logger.Error("failed to set proxy on http client default session: %w", err)
+			c.logError(req, "failed to set header on http client default session", err)
 
 			return nil, err
 		}
@@ -171,7 +293,7 @@ func (c *httpClient) Do(req *http.Request) (*http.Response, error) {
 	if req.Body != nil {
 		bodyBytes, err := ioutil.ReadAll(req.Body)
 		if err != nil {
-			c.logger.Error("failed to prepare helheim request body: %w", err)
+			c.logError(req, "failed to prepare helheim request body", err)
 
 			return nil, err
 		}
@@ -190,20 +312,47 @@ func (c *httpClient) Do(req *http.Request) (*http.Response, error) {
 		Options: opts,
 	}
 
-	resp, err := c.session.Request(reqOpts)
+	if c.config.rateLimiter != nil {
+		var release func()
+
+		ctx, release, err = c.config.rateLimiter.acquireContext(ctx, req.URL.Hostname())
+		if err != nil {
+			c.logError(req, "failed to acquire rate limit for http client request", err)
+			return nil, err
+		}
+
+		defer release()
+	}
+
+	resp, err := session.RequestContext(ctx, reqOpts)
 
 	if err != nil {
-		c.logger.Error("failed to get response on http client default session: %w", err)
+		c.logError(req, "failed to get response on http client default session", err)
 		return nil, err
 	}
 
 	if resp.Error {
-		err = fmt.Errorf("received error response from helheim: %s", resp.ErrorMsg)
-		c.logger.Error("failed to get response on http client default session: %w", err)
+		err = helheimResponseError(resp.ErrorMsg)
+		c.logError(req, "failed to get response on http client default session", err)
 
 		return nil, err
 	}
 
+	responseBody := resp.Response.Body
+
+	if c.config.isBase64Response {
+		data, err := base64.StdEncoding.DecodeString(resp.Response.Content)
+
+		if err != nil {
+			return nil, err
+		}
+
+		responseBody = string(data)
+	}
+
+	// the challenge is detected on the decoded body, base64 content would hide all markers
+	challenge := ClassifyResponse(resp.Response.StatusCode, resp.Response.Headers, responseBody)
+
 	response := &http.Response{
 		Status:     fmt.Sprintf("Status Code: %d", resp.Response.StatusCode),
 		StatusCode: resp.Response.StatusCode,
@@ -216,31 +365,70 @@ func (c *httpClient) Do(req *http.Request) (*http.Response, error) {
 		//Close:            false,
 		//Uncompressed:     false,
 		//Trailer:          nil,
-		Request: req,
+		Request: withChallenge(req, challenge),
 		//TLS:              nil,
+		Body: io.NopCloser(strings.NewReader(responseBody)),
 	}
 
-	if c.config.isBase64Response {
-		data, err := base64.StdEncoding.DecodeString(resp.Response.Content)
+	return response, nil
+}
+
+// applySessionSettings sets wokou and proxy on the session only if they differ from what was applied before.
+func (c *httpClient) applySessionSettings(ctx context.Context, req *http.Request, session ContextSession) error {
+	c.appliedLck.Lock()
+	defer c.appliedLck.Unlock()
+
+	// the applied state belongs to the current session, a session replaced meanwhile is left as it is
+	if session != c.currentSession() {
+		return nil
+	}
+
+	if c.config.wokouBrowser != "" && c.config.wokouBrowser != c.applied.WokouBrowser {
+		wokouResp, err := session.WokouContext(ctx, c.config.wokouBrowser)
 
 		if err != nil {
-			return nil, err
+			c.logError(req, "failed to set wokou on http client default session", err)
+			return err
 		}
 
-		response.Body = io.NopCloser(bytes.NewReader(data))
-	} else {
-		response.Body = io.NopCloser(strings.NewReader(resp.Response.Body))
+		if wokouResp.Error {
+			err = fmt.Errorf("received error response from helheim: %s", wokouResp.ErrorMsg)
+			c.logError(req, "failed to set wokou on http client default session", err)
+
+			return err
+		}
+
+		c.applied.WokouBrowser = c.config.wokouBrowser
 	}
 
-	return response, nil
+	// an empty proxy url clears a proxy applied before
+	if c.config.proxyUrl != c.applied.ProxyUrl {
+		proxyResp, err := session.SetProxyContext(ctx, c.config.proxyUrl)
+
+		if err != nil {
+			c.logError(req, "failed to set proxy on http client default session", err)
+			return err
+		}
+
+		if proxyResp.Error {
+			err = fmt.Errorf("received error response from helheim: %s", proxyResp.ErrorMsg)
+			c.logError(req, "failed to set proxy on http client default session", err)
+
+			return err
+		}
+
+		c.applied.ProxyUrl = c.config.proxyUrl
+	}
+
+	return nil
 }
 
 func (c *httpClient) GetSessionHeaders() map[string]string {
-	return c.session.GetHeaders()
+	return c.currentSession().GetHeaders()
 }
 
 func (c *httpClient) GetSessionCookies() []SessionCookie {
-	return c.session.GetCookies()
+	return c.currentSession().GetCookies()
 }
 
 func toGoHeader(hdrMap map[string]string) http.Header {
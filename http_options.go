@@ -7,6 +7,10 @@ type httpClientConfig struct {
 	debug            bool
 	wokouBrowser     string
 	isBase64Response bool
+	proxyPool        *ProxyPool
+	proxyBlockStatus []int
+	recovery         *recoveryConfig
+	rateLimiter      *RateLimiter
 }
 
 func WithProxyUrl(proxyUrl string) HttpClientOption {
@@ -32,3 +36,20 @@ func WithDebug() HttpClientOption {
 		config.debug = true
 	}
 }
+
+// WithProxyPool takes the proxy from the pool and rotates to the next one when a request fails with a proxy error
+// or one of the given block status codes. The block status codes default to 403, 407 and 429.
+// A proxy set with WithProxyUrl or SetProxy takes precedence and is never rotated.
+func WithProxyPool(pool *ProxyPool, blockStatusCodes ...int) HttpClientOption {
+	return func(config *httpClientConfig) {
+		config.proxyPool = pool
+		config.proxyBlockStatus = blockStatusCodes
+	}
+}
+
+// WithHttpRateLimiter throttles the requests of this HttpClient only. Retries after a session recovery are throttled as well.
+func WithHttpRateLimiter(limiter *RateLimiter) HttpClientOption {
+	return func(config *httpClientConfig) {
+		config.rateLimiter = limiter
+	}
+}
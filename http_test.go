@@ -0,0 +1,299 @@
+package helheim_go
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeHelheim is an in-memory Helheim backend counting every call per operation.
+type fakeHelheim struct {
+	lck           sync.Mutex
+	calls         map[string]int
+	nextSessionId int
+	proxies       map[int]string
+	headers       map[int]map[string]string
+	debug         map[int]DebugLevel
+	cookies       map[int][]SessionCookie
+	onRequest     func(sessionId int, options RequestOptions) (*RequestResponse, error)
+	onDelete      func(sessionId int)
+	deleteErr     error
+	balance       BalanceResponse
+}
+
+func newFakeHelheim() *fakeHelheim {
+	return &fakeHelheim{
+		calls:   make(map[string]int),
+		proxies: make(map[int]string),
+		headers: make(map[int]map[string]string),
+		debug:   make(map[int]DebugLevel),
+		cookies: make(map[int][]SessionCookie),
+	}
+}
+
+func (f *fakeHelheim) record(operation string) {
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	f.calls[operation]++
+}
+
+func (f *fakeHelheim) count(operation string) int {
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	return f.calls[operation]
+}
+
+func (f *fakeHelheim) proxy(sessionId int) string {
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	return f.proxies[sessionId]
+}
+
+func (f *fakeHelheim) Auth() (*AuthResponse, error) {
+	f.record("auth")
+
+	return &AuthResponse{Response: "authenticated"}, nil
+}
+
+func (f *fakeHelheim) GetBalance() (*BalanceResponse, error) {
+	f.record("get_balance")
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	balance := f.balance
+
+	return &balance, nil
+}
+
+func (f *fakeHelheim) setBalance(balance int, expiry time.Time, expired bool) {
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	f.balance.Response.Balance = balance
+	f.balance.Response.Expiry = int(expiry.Unix())
+	f.balance.Response.IsExpired = expired
+}
+
+func (f *fakeHelheim) CreateSession(options CreateSessionOptions) (*SessionResponse, error) {
+	f.record("create_session")
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	f.nextSessionId++
+
+	return &SessionResponse{
+		SessionAwareResponse: SessionAwareResponse{SessionId: f.nextSessionId},
+		Headers:              map[string]string{"User-Agent": "fake"},
+	}, nil
+}
+
+func (f *fakeHelheim) DeleteSession(sessionId int) (*SessionDeleteResponse, error) {
+	f.record("delete_session")
+
+	if f.onDelete != nil {
+		f.onDelete(sessionId)
+	}
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	if f.deleteErr != nil {
+		return nil, f.deleteErr
+	}
+
+	return &SessionDeleteResponse{SessionAwareResponse: SessionAwareResponse{SessionId: sessionId}}, nil
+}
+
+func (f *fakeHelheim) Debug(sessionId int, level DebugLevel) (*DebugResponse, error) {
+	f.record("debug")
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	f.debug[sessionId] = level
+
+	return &DebugResponse{SessionAwareResponse: SessionAwareResponse{SessionId: sessionId}}, nil
+}
+
+func (f *fakeHelheim) Request(sessionId int, options RequestOptions) (*RequestResponse, error) {
+	f.record("request")
+
+	resp := &RequestResponse{
+		SessionAwareResponse: SessionAwareResponse{SessionId: sessionId},
+		Response:             RequestResponseResponse{StatusCode: 200, Body: "ok"},
+	}
+
+	var err error
+
+	if f.onRequest != nil {
+		resp, err = f.onRequest(sessionId, options)
+	}
+
+	// like helheim every response carries the cookies of the session
+	if resp != nil && resp.Session.Cookies == nil {
+		f.lck.Lock()
+		resp.Session.Cookies = append([]SessionCookie(nil), f.cookies[sessionId]...)
+		f.lck.Unlock()
+	}
+
+	return resp, err
+}
+
+func (f *fakeHelheim) Wokou(sessionId int, browser string) (*WokouResponse, error) {
+	f.record("wokou")
+
+	return &WokouResponse{SessionAwareResponse: SessionAwareResponse{SessionId: sessionId}}, nil
+}
+
+func (f *fakeHelheim) SetProxy(sessionId int, proxy string) (*SetProxyResponse, error) {
+	f.record("set_proxy")
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	f.proxies[sessionId] = proxy
+
+	return &SetProxyResponse{SessionAwareResponse: SessionAwareResponse{SessionId: sessionId}}, nil
+}
+
+func (f *fakeHelheim) SetHeaders(sessionId int, headers map[string]string) (*SetHeadersResponse, error) {
+	f.record("set_headers")
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	f.headers[sessionId] = headers
+
+	return &SetHeadersResponse{SessionAwareResponse: SessionAwareResponse{SessionId: sessionId}}, nil
+}
+
+func (f *fakeHelheim) SetCookie(sessionId int, cookie SessionCookie) (*ModifyCookiesResponse, error) {
+	f.record("set_cookie")
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+
+	f.cookies[sessionId] = append(f.cookies[sessionId], cookie)
+
+	return &ModifyCookiesResponse{Cookies: append([]SessionCookie(nil), f.cookies[sessionId]...)}, nil
+}
+
+func (f *fakeHelheim) DelCookie(sessionId int, cookieName string) (*ModifyCookiesResponse, error) {
+	f.record("del_cookie")
+
+	return &ModifyCookiesResponse{}, nil
+}
+
+func (f *fakeHelheim) SetKasada(sessionId int, options KasadaOptions) (*SetKasadaResponse, error) {
+	f.record("set_kasada")
+
+	return &SetKasadaResponse{}, nil
+}
+
+func (f *fakeHelheim) SetKasadaHooks(sessionId int, options KasadaHooksOptions) (*SetKasadaHooksResponse, error) {
+	f.record("set_kasada_hooks")
+
+	return &SetKasadaHooksResponse{}, nil
+}
+
+func (f *fakeHelheim) SetLogger(logger Logger) {}
+
+func newTestHttpClient(t *testing.T, backend *fakeHelheim, options ...HttpClientOption) HttpClient {
+	t.Helper()
+
+	c, err := NewClientWithOptions("api-key", WithBackend(backend))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	httpClient, err := c.NewHttpClient(CreateSessionOptions{}, options...)
+	if err != nil {
+		t.Fatalf("failed to create http client: %v", err)
+	}
+
+	return httpClient
+}
+
+func TestHttpClientAppliesSessionSettingsOnce(t *testing.T) {
+	backend := newFakeHelheim()
+	httpClient := newTestHttpClient(t, backend, WithProxyUrl("http://127.0.0.1:8080"), WithWokou("chrome"), WithDebug())
+
+	for i := 0; i < 3; i++ {
+		_, err := httpClient.Get("https://example.com")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	expected := map[string]int{"set_proxy": 1, "wokou": 1, "debug": 1, "request": 3}
+
+	for operation, count := range expected {
+		if backend.count(operation) != count {
+			t.Errorf("expected %d %s calls, got %d", count, operation, backend.count(operation))
+		}
+	}
+
+	state := httpClient.GetAppliedState()
+
+	if state.ProxyUrl != "http://127.0.0.1:8080" || state.WokouBrowser != "chrome" || state.Debug != DebugLevelOn {
+		t.Errorf("unexpected applied state: %+v", state)
+	}
+}
+
+func TestHttpClientSetProxyClearsAppliedProxy(t *testing.T) {
+	backend := newFakeHelheim()
+	httpClient := newTestHttpClient(t, backend, WithProxyUrl("http://127.0.0.1:8080"))
+
+	_, err := httpClient.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	httpClient.SetProxy("")
+
+	_, err = httpClient.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if backend.count("set_proxy") != 2 {
+		t.Fatalf("expected the proxy to be cleared on the session, got %d set_proxy calls", backend.count("set_proxy"))
+	}
+
+	if proxy := backend.proxy(1); proxy != "" {
+		t.Errorf("expected no proxy on the session, got %q", proxy)
+	}
+
+	if state := httpClient.GetAppliedState(); state.ProxyUrl != "" {
+		t.Errorf("expected no applied proxy, got %q", state.ProxyUrl)
+	}
+}
+
+func TestHttpClientSetProxyKeepsPreviousProxyWhenInvalid(t *testing.T) {
+	backend := newFakeHelheim()
+	httpClient := newTestHttpClient(t, backend, WithProxyUrl("127.0.0.1:8080"))
+
+	httpClient.SetProxy("ftp://127.0.0.1:21")
+
+	if proxy := httpClient.GetProxy(); proxy != "http://127.0.0.1:8080" {
+		t.Fatalf("expected the previous proxy to be kept, got %q", proxy)
+	}
+}
+
+func TestNewHttpClientRejectsInvalidProxy(t *testing.T) {
+	c, err := NewClientWithOptions("api-key", WithBackend(newFakeHelheim()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = c.NewHttpClient(CreateSessionOptions{}, WithProxyUrl("127.0.0.1"))
+	if !errors.Is(err, ErrInvalidProxy) {
+		t.Fatalf("expected an invalid proxy error, got %v", err)
+	}
+}
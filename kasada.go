@@ -0,0 +1,97 @@
+package helheim_go
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// KasadaOptions configures which requests of a session helheim treats as protected by Kasada.
+type KasadaOptions struct {
+	ProtectedHosts []KasadaProtectedHost `json:"protected,omitempty"`
+	Headers        KasadaHeaderOptions   `json:"headers"`
+}
+
+type KasadaProtectedHost struct {
+	Host string `json:"host"`
+	// Paths limits the protection to the given paths. All paths of the host are protected when empty.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// KasadaHeaderOptions controls whether the x-kpsdk-ct and x-kpsdk-cd headers are attached to protected requests.
+type KasadaHeaderOptions struct {
+	Ct bool `json:"ct"`
+	Cd bool `json:"cd"`
+}
+
+// KasadaHooksOptions registers endpoints helheim calls with the Kasada tokens of protected requests.
+type KasadaHooksOptions struct {
+	Hooks []KasadaHook `json:"hooks,omitempty"`
+}
+
+type KasadaHook struct {
+	Host     string `json:"host"`
+	Path     string `json:"path,omitempty"`
+	Endpoint string `json:"endpoint"`
+}
+
+func (o KasadaOptions) Validate() error {
+	validationErr := &ValidationError{}
+
+	if len(o.ProtectedHosts) == 0 {
+		validationErr.add("ProtectedHosts", "at least one protected host is required")
+	}
+
+	for i, host := range o.ProtectedHosts {
+		field := fmt.Sprintf("ProtectedHosts[%d]", i)
+
+		validateKasadaHost(validationErr, field+".Host", host.Host)
+
+		for j, path := range host.Paths {
+			validateKasadaPath(validationErr, fmt.Sprintf("%s.Paths[%d]", field, j), path)
+		}
+	}
+
+	return validationErr.errOrNil()
+}
+
+func (o KasadaHooksOptions) Validate() error {
+	validationErr := &ValidationError{}
+
+	if len(o.Hooks) == 0 {
+		validationErr.add("Hooks", "at least one hook is required")
+	}
+
+	for i, hook := range o.Hooks {
+		field := fmt.Sprintf("Hooks[%d]", i)
+
+		validateKasadaHost(validationErr, field+".Host", hook.Host)
+
+		if hook.Path != "" {
+			validateKasadaPath(validationErr, field+".Path", hook.Path)
+		}
+
+		endpoint, err := url.Parse(hook.Endpoint)
+
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			validationErr.add(field+".Endpoint", "must be an absolute http or https url, got %q", hook.Endpoint)
+		}
+	}
+
+	return validationErr.errOrNil()
+}
+
+func validateKasadaHost(validationErr *ValidationError, field string, host string) {
+	switch {
+	case host == "":
+		validationErr.add(field, "must not be empty")
+	case strings.Contains(host, "://") || strings.ContainsAny(host, "/ "):
+		validationErr.add(field, "must be a plain host name without scheme or path, got %q", host)
+	}
+}
+
+func validateKasadaPath(validationErr *ValidationError, field string, path string) {
+	if !strings.HasPrefix(path, "/") {
+		validationErr.add(field, "must start with /, got %q", path)
+	}
+}
@@ -0,0 +1,89 @@
+package helheim_go
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+
+	return data
+}
+
+// assertJSONFixture compares the marshalled value with the fixture regardless of formatting and key order.
+func assertJSONFixture(t *testing.T, name string, value interface{}) {
+	t.Helper()
+
+	actualJson, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("failed to marshal %T: %v", value, err)
+	}
+
+	var expected, actual interface{}
+
+	if err := json.Unmarshal(readFixture(t, name), &expected); err != nil {
+		t.Fatalf("failed to parse fixture %s: %v", name, err)
+	}
+
+	if err := json.Unmarshal(actualJson, &actual); err != nil {
+		t.Fatalf("failed to parse marshalled %T: %v", value, err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("%T does not match fixture %s:\n got: %s\nwant: %s", value, name, actualJson, readFixture(t, name))
+	}
+}
+
+func TestKasadaOptionsMatchFixtures(t *testing.T) {
+	options := KasadaOptions{
+		ProtectedHosts: []KasadaProtectedHost{
+			{Host: "www.example.com", Paths: []string{"/api/checkout", "/api/cart"}},
+			{Host: "api.example.com"},
+		},
+		Headers: KasadaHeaderOptions{Ct: true},
+	}
+
+	hooks := KasadaHooksOptions{
+		Hooks: []KasadaHook{
+			{Host: "www.example.com", Path: "/api/checkout", Endpoint: "https://hooks.example.com/kasada"},
+		},
+	}
+
+	if err := options.Validate(); err != nil {
+		t.Fatalf("expected the fixture options to be valid: %v", err)
+	}
+
+	if err := hooks.Validate(); err != nil {
+		t.Fatalf("expected the fixture hooks to be valid: %v", err)
+	}
+
+	assertJSONFixture(t, "kasada/set_kasada_request.json", options)
+	assertJSONFixture(t, "kasada/set_kasada_hooks_request.json", hooks)
+}
+
+func TestKasadaResponsesDecodeFixtures(t *testing.T) {
+	h := &helheim{logger: NewNoopLogger()}
+
+	resp := SetKasadaResponse{}
+	err := h.handleResponse(string(readFixture(t, "kasada/set_kasada_response.json")), &resp)
+
+	if err != nil || resp.SessionId != 7 {
+		t.Fatalf("expected session 7 without error, got %+v: %v", resp, err)
+	}
+
+	err = h.handleResponse(string(readFixture(t, "kasada/set_kasada_error_response.json")), &SetKasadaHooksResponse{})
+
+	if !errors.Is(err, ErrHelheimResponse) {
+		t.Fatalf("expected ErrHelheimResponse, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package helheim_go
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LeveledLoggerOption func(config *leveledLoggerConfig)
+
+type leveledLoggerConfig struct {
+	minLevel        Level
+	componentLevels map[string]Level
+	timeFormat      string
+	prefix          string
+}
+
+// WithMinLevel drops all entries below the given level. Defaults to LevelInfo.
+func WithMinLevel(level Level) LeveledLoggerOption {
+	return func(config *leveledLoggerConfig) {
+		config.minLevel = level
+	}
+}
+
+// WithComponentLevel overrides the minimum level for the entries of one component like ComponentAuth, ComponentSession or ComponentHttp.
+// Use LevelOff to silence a component.
+func WithComponentLevel(component string, level Level) LeveledLoggerOption {
+	return func(config *leveledLoggerConfig) {
+		config.componentLevels[component] = level
+	}
+}
+
+// WithTimeFormat sets the layout of the timestamp each entry starts with. An empty layout omits the timestamp. Defaults to time.RFC3339.
+func WithTimeFormat(layout string) LeveledLoggerOption {
+	return func(config *leveledLoggerConfig) {
+		config.timeFormat = layout
+	}
+}
+
+func WithPrefix(prefix string) LeveledLoggerOption {
+	return func(config *leveledLoggerConfig) {
+		config.prefix = prefix
+	}
+}
+
+type leveledLogger struct {
+	writer  io.Writer
+	config  *leveledLoggerConfig
+	writeMu sync.Mutex
+}
+
+// NewLeveledLogger writes one line per entry to the given writer, e.g.
+// 2006-01-02T15:04:05Z07:00 INFO created new session component=client operation=create_session session_id=1
+func NewLeveledLogger(writer io.Writer, options ...LeveledLoggerOption) StructuredLogger {
+	config := &leveledLoggerConfig{
+		minLevel:        LevelInfo,
+		componentLevels: make(map[string]Level),
+		timeFormat:      time.RFC3339,
+	}
+
+	for _, opt := range options {
+		opt(config)
+	}
+
+	return &leveledLogger{
+		writer: writer,
+		config: config,
+	}
+}
+
+func (l *leveledLogger) Debug(format string, args ...interface{}) {
+	l.Log(LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Info(format string, args ...interface{}) {
+	l.Log(LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Warn(format string, args ...interface{}) {
+	l.Log(LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Error(format string, args ...interface{}) {
+	l.Log(LevelError, fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Log(level Level, msg string, fields ...Field) {
+	if !l.enabled(level, fields) {
+		return
+	}
+
+	b := strings.Builder{}
+
+	if l.config.timeFormat != "" {
+		b.WriteString(time.Now().Format(l.config.timeFormat))
+		b.WriteString(" ")
+	}
+
+	b.WriteString(l.config.prefix)
+	b.WriteString(strings.ToUpper(level.String()))
+	b.WriteString(" ")
+	b.WriteString(formatFields(msg, fields))
+	b.WriteString("\n")
+
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+
+	_, _ = io.WriteString(l.writer, b.String())
+}
+
+func (l *leveledLogger) enabled(level Level, fields []Field) bool {
+	minLevel := l.config.minLevel
+
+	for _, field := range fields {
+		if field.Key != FieldComponent {
+			continue
+		}
+
+		if component, ok := field.Value.(string); ok {
+			if componentLevel, ok := l.config.componentLevels[component]; ok {
+				minLevel = componentLevel
+			}
+		}
+	}
+
+	return minLevel != LevelOff && level >= minLevel
+}
@@ -1,6 +1,11 @@
 package helheim_go
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
 
 type Logger interface {
 	Debug(format string, args ...interface{})
@@ -77,3 +82,219 @@ func (n logger) Warn(format string, args ...interface{}) {
 func (n logger) Error(format string, args ...interface{}) {
 	fmt.Printf(format+"\n", args...)
 }
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	// LevelOff disables logging when used as minimum level.
+	LevelOff
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelOff:
+		return "off"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+const (
+	ComponentClient  = "client"
+	ComponentAuth    = "auth"
+	ComponentSession = "session"
+	ComponentHttp    = "http"
+)
+
+const (
+	FieldComponent  = "component"
+	FieldOperation  = "operation"
+	FieldSessionId  = "session_id"
+	FieldUrl        = "url"
+	FieldStatusCode = "status_code"
+	FieldDuration   = "duration"
+	FieldError      = "error"
+	FieldPayload    = "payload"
+)
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// StructuredLogger is implemented by loggers which keep the fields of a log entry separate from the message.
+// Loggers only implementing Logger receive the fields appended to the message as key=value pairs.
+type StructuredLogger interface {
+	Logger
+	Log(level Level, msg string, fields ...Field)
+}
+
+func logFields(logger Logger, level Level, msg string, fields ...Field) {
+	if structured, ok := logger.(StructuredLogger); ok {
+		structured.Log(level, msg, fields...)
+		return
+	}
+
+	line := formatFields(msg, fields)
+
+	switch level {
+	case LevelDebug:
+		logger.Debug("%s", line)
+	case LevelInfo:
+		logger.Info("%s", line)
+	case LevelWarn:
+		logger.Warn("%s", line)
+	default:
+		logger.Error("%s", line)
+	}
+}
+
+func formatFields(msg string, fields []Field) string {
+	b := strings.Builder{}
+	b.WriteString(msg)
+
+	for _, field := range fields {
+		b.WriteString(" ")
+		b.WriteString(field.Key)
+		b.WriteString("=")
+
+		value := fmt.Sprint(field.Value)
+
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+
+		b.WriteString(value)
+	}
+
+	return b.String()
+}
+
+type componentLogger struct {
+	logger    Logger
+	component string
+}
+
+// withComponent tags all entries of the logger with the given component. An existing component tag is replaced.
+func withComponent(logger Logger, component string) Logger {
+	if logger == nil {
+		logger = NewNoopLogger()
+	}
+
+	if l, ok := logger.(*componentLogger); ok {
+		logger = l.logger
+	}
+
+	return &componentLogger{
+		logger:    logger,
+		component: component,
+	}
+}
+
+func (l *componentLogger) Debug(format string, args ...interface{}) {
+	l.Log(LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *componentLogger) Info(format string, args ...interface{}) {
+	l.Log(LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *componentLogger) Warn(format string, args ...interface{}) {
+	l.Log(LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *componentLogger) Error(format string, args ...interface{}) {
+	l.Log(LevelError, fmt.Sprintf(format, args...))
+}
+
+// Log only tags structured loggers with the component. Plain loggers receive the message as before.
+func (l *componentLogger) Log(level Level, msg string, fields ...Field) {
+	if isStructured(l.logger) {
+		fields = append([]Field{F(FieldComponent, l.component)}, fields...)
+	}
+
+	logFields(l.logger, level, msg, fields...)
+}
+
+// isStructured looks through the internal logger wrappers to the logger finally receiving the entries.
+func isStructured(logger Logger) bool {
+	switch l := logger.(type) {
+	case *componentLogger:
+		return isStructured(l.logger)
+	case *redactingLogger:
+		return isStructured(l.logger)
+	case *swappableLogger:
+		return isStructured(l.current())
+	}
+
+	_, ok := logger.(StructuredLogger)
+
+	return ok
+}
+
+// swappableLogger passes all entries on to a logger which can be replaced at runtime, see Client.SetLogger.
+type swappableLogger struct {
+	lck    sync.RWMutex
+	logger Logger
+}
+
+func newSwappableLogger(logger Logger) *swappableLogger {
+	l := &swappableLogger{}
+	l.set(logger)
+
+	return l
+}
+
+func (l *swappableLogger) set(logger Logger) {
+	if logger == nil {
+		logger = NewNoopLogger()
+	}
+
+	l.lck.Lock()
+	defer l.lck.Unlock()
+
+	l.logger = logger
+}
+
+func (l *swappableLogger) current() Logger {
+	l.lck.RLock()
+	defer l.lck.RUnlock()
+
+	return l.logger
+}
+
+func (l *swappableLogger) Debug(format string, args ...interface{}) {
+	l.current().Debug(format, args...)
+}
+
+func (l *swappableLogger) Info(format string, args ...interface{}) {
+	l.current().Info(format, args...)
+}
+
+func (l *swappableLogger) Warn(format string, args ...interface{}) {
+	l.current().Warn(format, args...)
+}
+
+func (l *swappableLogger) Error(format string, args ...interface{}) {
+	l.current().Error(format, args...)
+}
+
+func (l *swappableLogger) Log(level Level, msg string, fields ...Field) {
+	logFields(l.current(), level, msg, fields...)
+}
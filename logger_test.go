@@ -0,0 +1,52 @@
+package helheim_go
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComponentOnlyTagsStructuredLoggers(t *testing.T) {
+	plain := &capturingLogger{}
+	structured := &capturingStructuredLogger{}
+
+	withComponent(NewRedactingLogger(plain), ComponentAuth).Info("initiated helheim")
+	withComponent(NewRedactingLogger(structured), ComponentAuth).Info("initiated helheim")
+
+	if plain.output() != "initiated helheim" {
+		t.Errorf("expected the plain message, got %q", plain.output())
+	}
+
+	if !strings.Contains(structured.output(), `Key:"component", Value:"auth"`) {
+		t.Errorf("expected a component field, got %q", structured.output())
+	}
+}
+
+func TestSetLoggerReachesExistingHttpClients(t *testing.T) {
+	before := &capturingStructuredLogger{}
+	after := &capturingStructuredLogger{}
+
+	c, err := NewClientWithOptions("api-key", WithBackend(newFakeHelheim()), WithLogger(before))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	httpClient, err := c.NewHttpClient(CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("failed to create http client: %v", err)
+	}
+
+	c.SetLogger(after)
+
+	_, err = httpClient.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("failed to request: %v", err)
+	}
+
+	if strings.Contains(before.output(), "http client request done") {
+		t.Errorf("the replaced logger still received entries:\n%s", before.output())
+	}
+
+	if !strings.Contains(after.output(), "http client request done") {
+		t.Errorf("the new logger did not receive the request entry:\n%s", after.output())
+	}
+}
@@ -0,0 +1,52 @@
+package logrus_logger
+
+import (
+	helheim_go "github.com/bogdanfinn/helheim-go"
+	"github.com/sirupsen/logrus"
+)
+
+type logger struct {
+	logger logrus.FieldLogger
+}
+
+// New adapts a logrus.FieldLogger to helheim_go.StructuredLogger.
+func New(l logrus.FieldLogger) helheim_go.StructuredLogger {
+	return &logger{logger: l}
+}
+
+func (l *logger) Debug(format string, args ...interface{}) {
+	l.logger.Debugf(format, args...)
+}
+
+func (l *logger) Info(format string, args ...interface{}) {
+	l.logger.Infof(format, args...)
+}
+
+func (l *logger) Warn(format string, args ...interface{}) {
+	l.logger.Warnf(format, args...)
+}
+
+func (l *logger) Error(format string, args ...interface{}) {
+	l.logger.Errorf(format, args...)
+}
+
+func (l *logger) Log(level helheim_go.Level, msg string, fields ...helheim_go.Field) {
+	logrusFields := make(logrus.Fields, len(fields))
+
+	for _, field := range fields {
+		logrusFields[field.Key] = field.Value
+	}
+
+	entry := l.logger.WithFields(logrusFields)
+
+	switch level {
+	case helheim_go.LevelDebug:
+		entry.Debug(msg)
+	case helheim_go.LevelInfo:
+		entry.Info(msg)
+	case helheim_go.LevelWarn:
+		entry.Warn(msg)
+	default:
+		entry.Error(msg)
+	}
+}
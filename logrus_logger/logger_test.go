@@ -0,0 +1,40 @@
+package logrus_logger
+
+import (
+	"testing"
+
+	helheim_go "github.com/bogdanfinn/helheim-go"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLogMapsLevelsAndFields(t *testing.T) {
+	levels := map[helheim_go.Level]logrus.Level{
+		helheim_go.LevelDebug: logrus.DebugLevel,
+		helheim_go.LevelInfo:  logrus.InfoLevel,
+		helheim_go.LevelWarn:  logrus.WarnLevel,
+		helheim_go.LevelError: logrus.ErrorLevel,
+		helheim_go.LevelOff:   logrus.ErrorLevel,
+		helheim_go.Level(42):  logrus.ErrorLevel,
+	}
+
+	for level, expected := range levels {
+		l, hook := test.NewNullLogger()
+		l.SetLevel(logrus.DebugLevel)
+
+		New(l).Log(level, "created new session", helheim_go.F("session_id", 3), helheim_go.F("operation", "create_session"))
+
+		entries := hook.AllEntries()
+		if len(entries) != 1 {
+			t.Fatalf("%s: expected one entry, got %d", level, len(entries))
+		}
+
+		if entries[0].Level != expected || entries[0].Message != "created new session" {
+			t.Fatalf("%s: expected %s entry, got %s %q", level, expected, entries[0].Level, entries[0].Message)
+		}
+
+		if entries[0].Data["session_id"] != 3 || entries[0].Data["operation"] != "create_session" {
+			t.Fatalf("%s: expected the fields as key values, got %v", level, entries[0].Data)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package helheim_go
+
+import "time"
+
+// Metrics receives the measurements of the client, its sessions and http clients.
+// See the prometheus_metrics package for a Prometheus implementation.
+type Metrics interface {
+	// ObserveRequest is called for every HttpClient request. statusCode is 0 when no response was received.
+	ObserveRequest(host string, method string, statusCode int, duration time.Duration)
+	// ObserveBackendCall is called for every helheim operation. errorClass is empty on success.
+	ObserveBackendCall(operation string, duration time.Duration, errorClass string)
+	SetLiveSessions(count int)
+	IncAuthRefresh(success bool)
+	SetBalance(balance int)
+}
+
+type noopMetrics struct{}
+
+func NewNoopMetrics() Metrics {
+	return &noopMetrics{}
+}
+
+func (m noopMetrics) ObserveRequest(host string, method string, statusCode int, duration time.Duration) {
+}
+
+func (m noopMetrics) ObserveBackendCall(operation string, duration time.Duration, errorClass string) {
+}
+
+func (m noopMetrics) SetLiveSessions(count int) {}
+
+func (m noopMetrics) IncAuthRefresh(success bool) {}
+
+func (m noopMetrics) SetBalance(balance int) {}
+
+// metricsHelheim decorates a Helheim backend and reports the duration and outcome of every operation.
+type metricsHelheim struct {
+	Helheim
+	metrics Metrics
+}
+
+func newMetricsHelheim(helheim Helheim, metrics Metrics) Helheim {
+	return &metricsHelheim{
+		Helheim: helheim,
+		metrics: metrics,
+	}
+}
+
+func (m *metricsHelheim) observe(operation string, start time.Time, err error) {
+	errorClass := ""
+
+	if err != nil {
+		errorClass = string(classifyError(err))
+	}
+
+	m.metrics.ObserveBackendCall(operation, time.Since(start), errorClass)
+}
+
+func (m *metricsHelheim) AuthStatus() AuthStatus {
+	return authStatusOf(m.Helheim)
+}
+
+func (m *metricsHelheim) needsReAuth() bool {
+	h, ok := m.Helheim.(lazyAuthHelheim)
+
+	return ok && h.needsReAuth()
+}
+
+func (m *metricsHelheim) reAuth() error {
+	if h, ok := m.Helheim.(lazyAuthHelheim); ok {
+		return h.reAuth()
+	}
+
+	return nil
+}
+
+func (m *metricsHelheim) Auth() (*AuthResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.Auth()
+	m.observe("auth", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) GetBalance() (*BalanceResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.GetBalance()
+	m.observe("get_balance", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) CreateSession(options CreateSessionOptions) (*SessionResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.CreateSession(options)
+	m.observe("create_session", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) DeleteSession(sessionId int) (*SessionDeleteResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.DeleteSession(sessionId)
+	m.observe("delete_session", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) Debug(sessionId int, level DebugLevel) (*DebugResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.Debug(sessionId, level)
+	m.observe("debug", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) Request(sessionId int, options RequestOptions) (*RequestResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.Request(sessionId, options)
+	m.observe("request", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) Wokou(sessionId int, browser string) (*WokouResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.Wokou(sessionId, browser)
+	m.observe("wokou", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) SetProxy(sessionId int, proxy string) (*SetProxyResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.SetProxy(sessionId, proxy)
+	m.observe("set_proxy", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) SetHeaders(sessionId int, headers map[string]string) (*SetHeadersResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.SetHeaders(sessionId, headers)
+	m.observe("set_headers", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) setOrderedHeaders(sessionId int, headers map[string]string, order []string) (*SetHeadersResponse, error) {
+	start := time.Now()
+	resp, err := setOrderedHeaders(m.Helheim, sessionId, headers, order)
+	m.observe("set_headers", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) SetCookie(sessionId int, cookie SessionCookie) (*ModifyCookiesResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.SetCookie(sessionId, cookie)
+	m.observe("set_cookie", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) DelCookie(sessionId int, cookieName string) (*ModifyCookiesResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.DelCookie(sessionId, cookieName)
+	m.observe("del_cookie", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) SetKasada(sessionId int, options KasadaOptions) (*SetKasadaResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.SetKasada(sessionId, options)
+	m.observe("set_kasada", start, err)
+
+	return resp, err
+}
+
+func (m *metricsHelheim) SetKasadaHooks(sessionId int, options KasadaHooksOptions) (*SetKasadaHooksResponse, error) {
+	start := time.Now()
+	resp, err := m.Helheim.SetKasadaHooks(sessionId, options)
+	m.observe("set_kasada_hooks", start, err)
+
+	return resp, err
+}
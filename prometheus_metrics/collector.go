@@ -0,0 +1,120 @@
+package prometheus_metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements helheim_go.Metrics and exposes the measurements as prometheus.Collector.
+//
+//	collector := prometheus_metrics.NewCollector("helheim")
+//	registry.MustRegister(collector)
+//	client, err := helheim_go.NewClientWithOptions(apiKey, helheim_go.WithMetrics(collector))
+type Collector struct {
+	requestDuration *prometheus.HistogramVec
+	requests        *prometheus.CounterVec
+	backendDuration *prometheus.HistogramVec
+	backendErrors   *prometheus.CounterVec
+	liveSessions    prometheus.Gauge
+	authRefreshes   *prometheus.CounterVec
+	balance         prometheus.Gauge
+}
+
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of http client requests sent through helheim.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+		}, []string{"host", "method"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Http client requests sent through helheim by status code. Status code 0 means no response was received.",
+		}, []string{"host", "method", "status_code"}),
+		backendDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "backend_call_duration_seconds",
+			Help:      "Duration of helheim backend operations.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		backendErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "backend_errors_total",
+			Help:      "Failed helheim backend operations by error class.",
+		}, []string{"operation", "error_class"}),
+		liveSessions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "live_sessions",
+			Help:      "Sessions currently registered on the client.",
+		}),
+		authRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_refreshes_total",
+			Help:      "Authentications against helheim by result.",
+		}, []string{"result"}),
+		balance: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "balance",
+			Help:      "Last known helheim balance.",
+		}),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.requestDuration,
+		c.requests,
+		c.backendDuration,
+		c.backendErrors,
+		c.liveSessions,
+		c.authRefreshes,
+		c.balance,
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+func (c *Collector) ObserveRequest(host string, method string, statusCode int, duration time.Duration) {
+	c.requestDuration.WithLabelValues(host, method).Observe(duration.Seconds())
+	c.requests.WithLabelValues(host, method, strconv.Itoa(statusCode)).Inc()
+}
+
+func (c *Collector) ObserveBackendCall(operation string, duration time.Duration, errorClass string) {
+	c.backendDuration.WithLabelValues(operation).Observe(duration.Seconds())
+
+	if errorClass != "" {
+		c.backendErrors.WithLabelValues(operation, errorClass).Inc()
+	}
+}
+
+func (c *Collector) SetLiveSessions(count int) {
+	c.liveSessions.Set(float64(count))
+}
+
+func (c *Collector) IncAuthRefresh(success bool) {
+	result := "success"
+
+	if !success {
+		result = "failure"
+	}
+
+	c.authRefreshes.WithLabelValues(result).Inc()
+}
+
+func (c *Collector) SetBalance(balance int) {
+	c.balance.Set(float64(balance))
+}
@@ -0,0 +1,81 @@
+package prometheus_metrics
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCollectorLabelSets(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector := NewCollector("helheim")
+	registry.MustRegister(collector)
+
+	collector.ObserveRequest("example.com", "GET", 200, 100*time.Millisecond)
+	collector.ObserveRequest("example.com", "GET", 0, time.Second)
+	collector.ObserveBackendCall("request", 50*time.Millisecond, "")
+	collector.ObserveBackendCall("create_session", 10*time.Millisecond, "helheim")
+	collector.SetLiveSessions(3)
+	collector.IncAuthRefresh(true)
+	collector.IncAuthRefresh(false)
+	collector.SetBalance(42)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	expected := map[string][]string{
+		"helheim_http_request_duration_seconds": {"host=example.com,method=GET"},
+		"helheim_http_requests_total":           {"host=example.com,method=GET,status_code=0", "host=example.com,method=GET,status_code=200"},
+		"helheim_backend_call_duration_seconds": {"operation=create_session", "operation=request"},
+		"helheim_backend_errors_total":          {"error_class=helheim,operation=create_session"},
+		"helheim_live_sessions":                 {""},
+		"helheim_auth_refreshes_total":          {"result=failure", "result=success"},
+		"helheim_balance":                       {""},
+	}
+
+	actual := make(map[string][]string, len(families))
+	for _, family := range families {
+		actual[family.GetName()] = labelSets(family)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected label sets:\n got: %v\nwant: %v", actual, expected)
+	}
+
+	values := map[string]float64{
+		"helheim_live_sessions": 3,
+		"helheim_balance":       42,
+	}
+
+	for _, family := range families {
+		value, ok := values[family.GetName()]
+		if ok && family.GetMetric()[0].GetGauge().GetValue() != value {
+			t.Errorf("%s: expected %v, got %v", family.GetName(), value, family.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+}
+
+func labelSets(family *dto.MetricFamily) []string {
+	var sets []string
+
+	for _, metric := range family.GetMetric() {
+		var pairs []string
+
+		for _, label := range metric.GetLabel() {
+			pairs = append(pairs, label.GetName()+"="+label.GetValue())
+		}
+
+		sets = append(sets, strings.Join(pairs, ","))
+	}
+
+	sort.Strings(sets)
+
+	return sets
+}
@@ -0,0 +1,442 @@
+package helheim_go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultProxyProbeUrl           = "https://www.gstatic.com/generate_204"
+	defaultProxyProbeTimeout       = 10 * time.Second
+	defaultProxyProbeInterval      = 1 * time.Minute
+	defaultProxyProbeConcurrency   = 5
+	defaultProxyUnhealthyThreshold = 2
+	defaultProxyHealthyThreshold   = 1
+)
+
+type ProxyHealthOption func(config *proxyHealthConfig)
+
+type proxyHealthConfig struct {
+	probeUrl           string
+	timeout            time.Duration
+	interval           time.Duration
+	concurrency        int
+	unhealthyThreshold int
+	healthyThreshold   int
+	clock              Clock
+	logger             Logger
+	onChange           func(health ProxyHealth)
+	pool               *ProxyPool
+}
+
+// WithProbeUrl sets the url which is requested through every proxy. Any status below 400 counts as healthy.
+func WithProbeUrl(probeUrl string) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.probeUrl = probeUrl
+	}
+}
+
+// WithProbeTimeout limits a single probe. Defaults to ten seconds.
+func WithProbeTimeout(timeout time.Duration) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.timeout = timeout
+	}
+}
+
+// WithProbeInterval sets how often the background checker probes all proxies. Defaults to one minute.
+func WithProbeInterval(interval time.Duration) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.interval = interval
+	}
+}
+
+// WithProbeConcurrency limits how many proxies are probed at the same time. Defaults to five.
+func WithProbeConcurrency(concurrency int) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.concurrency = concurrency
+	}
+}
+
+// WithHealthThresholds sets after how many consecutive failed probes a proxy becomes unhealthy
+// and after how many consecutive successful probes it becomes healthy again.
+func WithHealthThresholds(unhealthyAfter int, healthyAfter int) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.unhealthyThreshold = unhealthyAfter
+		config.healthyThreshold = healthyAfter
+	}
+}
+
+func WithProxyHealthClock(clock Clock) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.clock = clock
+	}
+}
+
+func WithProxyHealthLogger(logger Logger) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.logger = logger
+	}
+}
+
+// WithHealthChangeCallback is invoked whenever a proxy switches between healthy and unhealthy.
+func WithHealthChangeCallback(onChange func(health ProxyHealth)) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.onChange = onChange
+	}
+}
+
+// WithHealthProxyPool checks all proxies of the pool and takes unhealthy proxies out of its rotation
+// until they are healthy again.
+func WithHealthProxyPool(pool *ProxyPool) ProxyHealthOption {
+	return func(config *proxyHealthConfig) {
+		config.pool = pool
+	}
+}
+
+func (config *proxyHealthConfig) validate() error {
+	validationErr := &ValidationError{}
+
+	if config.timeout <= 0 {
+		validationErr.add("ProxyHealth.Timeout", "must be positive, got %s", config.timeout)
+	}
+
+	if config.interval <= 0 {
+		validationErr.add("ProxyHealth.Interval", "must be positive, got %s", config.interval)
+	}
+
+	if config.concurrency < 1 {
+		validationErr.add("ProxyHealth.Concurrency", "must be at least 1, got %d", config.concurrency)
+	}
+
+	if config.unhealthyThreshold < 1 {
+		validationErr.add("ProxyHealth.UnhealthyThreshold", "must be at least 1, got %d", config.unhealthyThreshold)
+	}
+
+	if config.healthyThreshold < 1 {
+		validationErr.add("ProxyHealth.HealthyThreshold", "must be at least 1, got %d", config.healthyThreshold)
+	}
+
+	return validationErr.errOrNil()
+}
+
+type ProxyHealth struct {
+	Proxy                string
+	Healthy              bool
+	Latency              time.Duration
+	LastCheckedAt        time.Time
+	LastError            error
+	ConsecutiveFailures  int
+	ConsecutiveSuccesses int
+	Checks               int64
+	Failures             int64
+}
+
+// ProxyHealthChecker probes proxies directly with net/http and keeps track of their health over time.
+// Proxies start healthy until they failed the configured amount of probes in a row.
+type ProxyHealthChecker struct {
+	config  *proxyHealthConfig
+	lck     sync.RWMutex
+	health  map[string]*ProxyHealth
+	proxies []string
+	stopLck sync.Mutex
+	stopCh  chan struct{}
+}
+
+// NewProxyHealthChecker fails with a ValidationError when a timeout, interval, concurrency or threshold is not positive.
+func NewProxyHealthChecker(proxies []string, options ...ProxyHealthOption) (*ProxyHealthChecker, error) {
+	config := &proxyHealthConfig{
+		probeUrl:           defaultProxyProbeUrl,
+		timeout:            defaultProxyProbeTimeout,
+		interval:           defaultProxyProbeInterval,
+		concurrency:        defaultProxyProbeConcurrency,
+		unhealthyThreshold: defaultProxyUnhealthyThreshold,
+		healthyThreshold:   defaultProxyHealthyThreshold,
+		clock:              NewRealClock(),
+		logger:             NewNoopLogger(),
+	}
+
+	for _, opt := range options {
+		opt(config)
+	}
+
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &ProxyHealthChecker{
+		config: config,
+		health: make(map[string]*ProxyHealth),
+	}
+
+	if config.pool != nil {
+		proxies = append(config.pool.urls(), proxies...)
+	}
+
+	for _, proxy := range proxies {
+		err := checker.Add(proxy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return checker, nil
+}
+
+// Add registers a proxy for health checking. Already known proxies are ignored.
+func (h *ProxyHealthChecker) Add(proxy string) error {
+	normalized, err := NormalizeProxy(proxy)
+	if err != nil {
+		return err
+	}
+
+	h.lck.Lock()
+	defer h.lck.Unlock()
+
+	if _, ok := h.health[normalized]; ok {
+		return nil
+	}
+
+	h.health[normalized] = &ProxyHealth{Proxy: normalized, Healthy: true}
+	h.proxies = append(h.proxies, normalized)
+
+	return nil
+}
+
+func (h *ProxyHealthChecker) Health(proxy string) (ProxyHealth, bool) {
+	normalized, err := NormalizeProxy(proxy)
+	if err != nil {
+		return ProxyHealth{}, false
+	}
+
+	h.lck.RLock()
+	defer h.lck.RUnlock()
+
+	health, ok := h.health[normalized]
+	if !ok {
+		return ProxyHealth{}, false
+	}
+
+	return *health, true
+}
+
+// IsHealthy reports unknown proxies as unhealthy.
+func (h *ProxyHealthChecker) IsHealthy(proxy string) bool {
+	health, ok := h.Health(proxy)
+
+	return ok && health.Healthy
+}
+
+func (h *ProxyHealthChecker) Healthy() []string {
+	h.lck.RLock()
+	defer h.lck.RUnlock()
+
+	var healthy []string
+
+	for _, proxy := range h.proxies {
+		if h.health[proxy].Healthy {
+			healthy = append(healthy, proxy)
+		}
+	}
+
+	return healthy
+}
+
+func (h *ProxyHealthChecker) Report() []ProxyHealth {
+	h.lck.RLock()
+	defer h.lck.RUnlock()
+
+	report := make([]ProxyHealth, 0, len(h.proxies))
+
+	for _, proxy := range h.proxies {
+		report = append(report, *h.health[proxy])
+	}
+
+	return report
+}
+
+// CheckAll probes all registered proxies with the configured concurrency and returns their updated health.
+func (h *ProxyHealthChecker) CheckAll(ctx context.Context) []ProxyHealth {
+	h.lck.RLock()
+	proxies := make([]string, len(h.proxies))
+	copy(proxies, h.proxies)
+	h.lck.RUnlock()
+
+	wg := sync.WaitGroup{}
+	semaphore := make(chan struct{}, h.config.concurrency)
+
+	for _, proxy := range proxies {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(proxy string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			h.check(ctx, proxy)
+		}(proxy)
+	}
+
+	wg.Wait()
+
+	return h.Report()
+}
+
+// Check probes a single proxy. Unknown proxies get registered first.
+func (h *ProxyHealthChecker) Check(ctx context.Context, proxy string) (ProxyHealth, error) {
+	err := h.Add(proxy)
+	if err != nil {
+		return ProxyHealth{}, err
+	}
+
+	normalized, _ := NormalizeProxy(proxy)
+
+	return h.check(ctx, normalized), nil
+}
+
+func (h *ProxyHealthChecker) check(ctx context.Context, proxy string) ProxyHealth {
+	start := h.config.clock.Now()
+	err := h.probe(ctx, proxy)
+	latency := h.config.clock.Now().Sub(start)
+
+	h.lck.Lock()
+
+	health := h.health[proxy]
+	wasHealthy := health.Healthy
+
+	health.Checks++
+	health.LastCheckedAt = h.config.clock.Now()
+	health.LastError = err
+
+	if err != nil {
+		health.Failures++
+		health.ConsecutiveFailures++
+		health.ConsecutiveSuccesses = 0
+
+		if health.ConsecutiveFailures >= h.config.unhealthyThreshold {
+			health.Healthy = false
+		}
+	} else {
+		health.Latency = latency
+		health.ConsecutiveSuccesses++
+		health.ConsecutiveFailures = 0
+
+		if health.ConsecutiveSuccesses >= h.config.healthyThreshold {
+			health.Healthy = true
+		}
+	}
+
+	result := *health
+	h.lck.Unlock()
+
+	if result.Healthy != wasHealthy {
+		logFields(h.config.logger, LevelInfo, "proxy health changed", F(FieldOperation, "proxy_health"), F("proxy", redactProxyCredentials(proxy)), F("healthy", result.Healthy))
+
+		if h.config.pool != nil {
+			h.config.pool.SetHealthy(proxy, result.Healthy)
+		}
+
+		if h.config.onChange != nil {
+			h.config.onChange(result)
+		}
+	}
+
+	return result
+}
+
+func (h *ProxyHealthChecker) probe(ctx context.Context, proxy string) error {
+	proxyUrl, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+
+	// net/http resolves hostnames on the proxy side for socks5 already
+	if proxyUrl.Scheme == ProxySchemeSocks5h {
+		proxyUrl.Scheme = ProxySchemeSocks5
+	}
+
+	transport := &http.Transport{
+		Proxy:             http.ProxyURL(proxyUrl),
+		DisableKeepAlives: true,
+	}
+	defer transport.CloseIdleConnections()
+
+	probeClient := &http.Client{
+		Transport: transport,
+		Timeout:   h.config.timeout,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.config.probeUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := probeClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("probe returned status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Start probes all proxies in the background every probe interval. A running checker is left untouched.
+func (h *ProxyHealthChecker) Start() {
+	h.stopLck.Lock()
+	defer h.stopLck.Unlock()
+
+	if h.stopCh != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	h.stopCh = stop
+
+	go h.run(stop)
+}
+
+func (h *ProxyHealthChecker) Stop() {
+	h.stopLck.Lock()
+	defer h.stopLck.Unlock()
+
+	if h.stopCh == nil {
+		return
+	}
+
+	close(h.stopCh)
+	h.stopCh = nil
+}
+
+func (h *ProxyHealthChecker) run(stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-h.config.clock.After(h.config.interval):
+			ctx, cancel := context.WithCancel(context.Background())
+
+			go func() {
+				select {
+				case <-stop:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			h.CheckAll(ctx)
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package helheim_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestProxy answers every proxied request itself, failing while broken is set.
+func newTestProxy(t *testing.T, broken *int32) *httptest.Server {
+	t.Helper()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(broken) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Cleanup(proxy.Close)
+
+	return proxy
+}
+
+func TestProxyHealthCheckerTakesUnhealthyProxiesOutOfThePool(t *testing.T) {
+	var healthy, broken int32 = 0, 1
+
+	healthyProxy := newTestProxy(t, &healthy)
+	brokenProxy := newTestProxy(t, &broken)
+
+	pool, err := NewProxyPool([]string{healthyProxy.URL, brokenProxy.URL})
+	if err != nil {
+		t.Fatalf("failed to create proxy pool: %v", err)
+	}
+
+	checker, err := NewProxyHealthChecker(nil, WithHealthProxyPool(pool), WithProbeUrl("http://probe.test/generate_204"), WithHealthThresholds(1, 1))
+	if err != nil {
+		t.Fatalf("failed to create health checker: %v", err)
+	}
+
+	report := checker.CheckAll(context.Background())
+	if len(report) != 2 {
+		t.Fatalf("expected the proxies of the pool to be checked, got %d", len(report))
+	}
+
+	if !checker.IsHealthy(healthyProxy.URL) || checker.IsHealthy(brokenProxy.URL) {
+		t.Fatalf("unexpected health report: %+v", report)
+	}
+
+	for i := 0; i < 4; i++ {
+		proxy, err := pool.Next(i)
+		if err != nil {
+			t.Fatalf("failed to get proxy: %v", err)
+		}
+
+		if proxy != healthyProxy.URL {
+			t.Fatalf("expected the unhealthy proxy to be skipped, got %q", proxy)
+		}
+	}
+
+	atomic.StoreInt32(&broken, 0)
+	checker.CheckAll(context.Background())
+
+	if stats := proxyStats(pool, brokenProxy.URL); stats.Unhealthy {
+		t.Fatalf("expected the recovered proxy to be back in rotation, got %+v", stats)
+	}
+}
+
+func TestNewProxyHealthCheckerRejectsInvalidOptions(t *testing.T) {
+	_, err := NewProxyHealthChecker(nil, WithProbeTimeout(0), WithProbeInterval(-time.Second), WithProbeConcurrency(0), WithHealthThresholds(0, 0))
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	var fields []string
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+
+	expected := []string{"ProxyHealth.Timeout", "ProxyHealth.Interval", "ProxyHealth.Concurrency", "ProxyHealth.UnhealthyThreshold", "ProxyHealth.HealthyThreshold"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected invalid fields %v, got %v", expected, fields)
+	}
+}
@@ -0,0 +1,346 @@
+package helheim_go
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ProxyStrategy string
+
+const (
+	ProxyStrategyRoundRobin    ProxyStrategy = "round_robin"
+	ProxyStrategyRandom        ProxyStrategy = "random"
+	ProxyStrategySticky        ProxyStrategy = "sticky"
+	ProxyStrategyLeastFailures ProxyStrategy = "least_failures"
+)
+
+const (
+	defaultProxyBanThreshold = 3
+	defaultProxyCooldown     = 5 * time.Minute
+)
+
+type ProxyPoolOption func(config *proxyPoolConfig)
+
+type proxyPoolConfig struct {
+	strategy     ProxyStrategy
+	banThreshold int
+	cooldown     time.Duration
+	clock        Clock
+}
+
+// WithProxyStrategy sets how the next proxy gets picked. Defaults to round robin.
+func WithProxyStrategy(strategy ProxyStrategy) ProxyPoolOption {
+	return func(config *proxyPoolConfig) {
+		config.strategy = strategy
+	}
+}
+
+// WithProxyBanThreshold sets after how many consecutive failures a proxy gets banned. Defaults to three.
+func WithProxyBanThreshold(failures int) ProxyPoolOption {
+	return func(config *proxyPoolConfig) {
+		config.banThreshold = failures
+	}
+}
+
+// WithProxyCooldown sets how long a banned proxy is skipped. Defaults to five minutes.
+func WithProxyCooldown(cooldown time.Duration) ProxyPoolOption {
+	return func(config *proxyPoolConfig) {
+		config.cooldown = cooldown
+	}
+}
+
+func WithProxyPoolClock(clock Clock) ProxyPoolOption {
+	return func(config *proxyPoolConfig) {
+		config.clock = clock
+	}
+}
+
+type ProxyStats struct {
+	Url                 string
+	Successes           int64
+	Failures            int64
+	ConsecutiveFailures int
+	BannedUntil         time.Time
+	Unhealthy           bool
+}
+
+type proxyEntry struct {
+	stats ProxyStats
+}
+
+// ProxyPool hands out proxies by the configured strategy and skips proxies that failed too often.
+type ProxyPool struct {
+	lck     sync.Mutex
+	config  *proxyPoolConfig
+	random  *rand.Rand
+	proxies []*proxyEntry
+	next    int
+	sticky  map[int]*proxyEntry
+}
+
+func NewProxyPool(proxies []string, options ...ProxyPoolOption) (*ProxyPool, error) {
+	config := &proxyPoolConfig{
+		strategy:     ProxyStrategyRoundRobin,
+		banThreshold: defaultProxyBanThreshold,
+		cooldown:     defaultProxyCooldown,
+		clock:        NewRealClock(),
+	}
+
+	for _, opt := range options {
+		opt(config)
+	}
+
+	switch config.strategy {
+	case ProxyStrategyRoundRobin, ProxyStrategyRandom, ProxyStrategySticky, ProxyStrategyLeastFailures:
+	default:
+		return nil, fmt.Errorf("unknown proxy strategy %q", config.strategy)
+	}
+
+	pool := &ProxyPool{
+		config: config,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		sticky: make(map[int]*proxyEntry),
+	}
+
+	seen := make(map[string]bool, len(proxies))
+
+	for _, proxy := range proxies {
+		if strings.TrimSpace(proxy) == "" {
+			continue
+		}
+
+		proxy, err := NormalizeProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[proxy] {
+			continue
+		}
+
+		seen[proxy] = true
+		pool.proxies = append(pool.proxies, &proxyEntry{stats: ProxyStats{Url: proxy}})
+	}
+
+	if len(pool.proxies) == 0 {
+		return nil, ErrNoProxyAvailable
+	}
+
+	return pool, nil
+}
+
+// NewProxyPoolFromFile loads one proxy per line. Empty lines and lines starting with # are ignored.
+func NewProxyPoolFromFile(path string, options ...ProxyPoolOption) (*ProxyPool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open proxy file: %w", err)
+	}
+	defer file.Close()
+
+	var proxies []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		proxies = append(proxies, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read proxy file: %w", err)
+	}
+
+	return NewProxyPool(proxies, options...)
+}
+
+// Next returns the proxy to use for the given session. Only the sticky strategy takes the session id into account.
+func (p *ProxyPool) Next(sessionId int) (string, error) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	now := p.config.clock.Now()
+
+	if p.config.strategy == ProxyStrategySticky {
+		if entry, ok := p.sticky[sessionId]; ok && entry.available(now) {
+			return entry.stats.Url, nil
+		}
+	}
+
+	entry := p.pick(now)
+	if entry == nil {
+		return "", ErrNoProxyAvailable
+	}
+
+	if p.config.strategy == ProxyStrategySticky {
+		p.sticky[sessionId] = entry
+	}
+
+	return entry.stats.Url, nil
+}
+
+func (p *ProxyPool) pick(now time.Time) *proxyEntry {
+	var available []*proxyEntry
+
+	switch p.config.strategy {
+	case ProxyStrategyRandom:
+		for _, entry := range p.proxies {
+			if entry.available(now) {
+				available = append(available, entry)
+			}
+		}
+
+		if len(available) == 0 {
+			return nil
+		}
+
+		return available[p.random.Intn(len(available))]
+	case ProxyStrategyLeastFailures:
+		var best *proxyEntry
+
+		for _, entry := range p.proxies {
+			if entry.available(now) && (best == nil || entry.stats.Failures < best.stats.Failures) {
+				best = entry
+			}
+		}
+
+		return best
+	default:
+		for i := 0; i < len(p.proxies); i++ {
+			entry := p.proxies[(p.next+i)%len(p.proxies)]
+
+			if entry.available(now) {
+				p.next = (p.next + i + 1) % len(p.proxies)
+				return entry
+			}
+		}
+
+		return nil
+	}
+}
+
+func (p *ProxyPool) ReportSuccess(proxy string) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	entry := p.find(proxy)
+	if entry == nil {
+		return
+	}
+
+	entry.stats.Successes++
+	entry.stats.ConsecutiveFailures = 0
+}
+
+// ReportFailure bans the proxy for the cool down once it reached the ban threshold of consecutive failures.
+func (p *ProxyPool) ReportFailure(proxy string) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	entry := p.find(proxy)
+	if entry == nil {
+		return
+	}
+
+	entry.stats.Failures++
+	entry.stats.ConsecutiveFailures++
+
+	if entry.stats.ConsecutiveFailures >= p.config.banThreshold {
+		entry.stats.BannedUntil = p.config.clock.Now().Add(p.config.cooldown)
+		entry.stats.ConsecutiveFailures = 0
+	}
+}
+
+func (p *ProxyPool) Ban(proxy string, duration time.Duration) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	entry := p.find(proxy)
+	if entry == nil {
+		return
+	}
+
+	entry.stats.BannedUntil = p.config.clock.Now().Add(duration)
+}
+
+// SetHealthy takes a proxy out of rotation until it is reported healthy again, e.g. by a ProxyHealthChecker.
+func (p *ProxyPool) SetHealthy(proxy string, healthy bool) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	entry := p.find(proxy)
+	if entry == nil {
+		return
+	}
+
+	entry.stats.Unhealthy = !healthy
+}
+
+// assign makes the proxy the sticky proxy of the session, e.g. when a replacement session keeps the proxy of the replaced one.
+func (p *ProxyPool) assign(sessionId int, proxy string) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	entry := p.find(proxy)
+	if entry == nil || p.config.strategy != ProxyStrategySticky {
+		return
+	}
+
+	p.sticky[sessionId] = entry
+}
+
+// Release forgets the sticky proxy assignment of the session.
+func (p *ProxyPool) Release(sessionId int) {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	delete(p.sticky, sessionId)
+}
+
+func (p *ProxyPool) Stats() []ProxyStats {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	stats := make([]ProxyStats, 0, len(p.proxies))
+
+	for _, entry := range p.proxies {
+		stats = append(stats, entry.stats)
+	}
+
+	return stats
+}
+
+func (p *ProxyPool) urls() []string {
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	urls := make([]string, 0, len(p.proxies))
+
+	for _, entry := range p.proxies {
+		urls = append(urls, entry.stats.Url)
+	}
+
+	return urls
+}
+
+func (p *ProxyPool) find(proxy string) *proxyEntry {
+	for _, entry := range p.proxies {
+		if entry.stats.Url == proxy {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func (e *proxyEntry) available(now time.Time) bool {
+	return !e.stats.Unhealthy && !now.Before(e.stats.BannedUntil)
+}
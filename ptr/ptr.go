@@ -0,0 +1,16 @@
+// Package ptr returns pointers to values for the optional fields of helheim_go.CreateSessionOptions.
+package ptr
+
+import "time"
+
+func Bool(value bool) *bool {
+	return &value
+}
+
+func Int(value int) *int {
+	return &value
+}
+
+func Duration(value time.Duration) *time.Duration {
+	return &value
+}
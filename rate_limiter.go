@@ -0,0 +1,349 @@
+package helheim_go
+
+import (
+	"context"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RateLimiterOption func(config *rateLimiterConfig)
+
+type hostRate struct {
+	perSecond float64
+	burst     int
+}
+
+type rateLimiterConfig struct {
+	hostRate        hostRate
+	hostRates       map[string]hostRate
+	maxInFlight     int
+	maxInFlightHost int
+	minDelay        time.Duration
+	maxDelay        time.Duration
+	clock           Clock
+	randSource      rand.Source
+}
+
+// WithHostRate limits every host to the given requests per second with the given burst. Unset means unlimited.
+func WithHostRate(perSecond float64, burst int) RateLimiterOption {
+	return func(config *rateLimiterConfig) {
+		config.hostRate = hostRate{perSecond: perSecond, burst: burst}
+	}
+}
+
+// WithHostRateFor overrides the rate of a single host.
+func WithHostRateFor(host string, perSecond float64, burst int) RateLimiterOption {
+	return func(config *rateLimiterConfig) {
+		config.hostRates[strings.ToLower(host)] = hostRate{perSecond: perSecond, burst: burst}
+	}
+}
+
+// WithMaxInFlight limits the concurrent requests across all hosts.
+func WithMaxInFlight(requests int) RateLimiterOption {
+	return func(config *rateLimiterConfig) {
+		config.maxInFlight = requests
+	}
+}
+
+// WithMaxInFlightPerHost limits the concurrent requests to a single host.
+func WithMaxInFlightPerHost(requests int) RateLimiterOption {
+	return func(config *rateLimiterConfig) {
+		config.maxInFlightHost = requests
+	}
+}
+
+// WithRandomDelay waits a random duration between minDelay and maxDelay before every request.
+func WithRandomDelay(minDelay time.Duration, maxDelay time.Duration) RateLimiterOption {
+	return func(config *rateLimiterConfig) {
+		config.minDelay = minDelay
+		config.maxDelay = maxDelay
+	}
+}
+
+func WithRateLimiterClock(clock Clock) RateLimiterOption {
+	return func(config *rateLimiterConfig) {
+		config.clock = clock
+	}
+}
+
+// WithRateLimiterRandSource makes the random delay deterministic.
+func WithRateLimiterRandSource(source rand.Source) RateLimiterOption {
+	return func(config *rateLimiterConfig) {
+		config.randSource = source
+	}
+}
+
+// bucketSweepInterval is how often token buckets which refilled completely are dropped.
+const bucketSweepInterval = time.Minute
+
+type tokenBucket struct {
+	rate   hostRate
+	tokens float64
+	last   time.Time
+}
+
+// full reports whether the bucket refilled up to its burst, it then equals a new bucket and can be dropped.
+func (b *tokenBucket) full(now time.Time, burst float64) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate.perSecond >= burst
+}
+
+// hostSlots limits the requests in flight to one host. It is dropped once no request holds or waits for a slot.
+type hostSlots struct {
+	slots chan struct{}
+	users int
+}
+
+// RateLimiter throttles requests per host with token buckets, caps the requests in flight and adds a random delay.
+// A request passing through several layers using the same limiter, e.g. HttpClient and Session, is only limited once.
+type RateLimiter struct {
+	config    *rateLimiterConfig
+	lck       sync.Mutex
+	random    *rand.Rand
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	inFlight  chan struct{}
+	hosts     map[string]*hostSlots
+}
+
+type rateLimiterContextKey struct {
+	limiter *RateLimiter
+}
+
+func NewRateLimiter(options ...RateLimiterOption) *RateLimiter {
+	config := &rateLimiterConfig{
+		hostRates:  make(map[string]hostRate),
+		clock:      NewRealClock(),
+		randSource: rand.NewSource(time.Now().UnixNano()),
+	}
+
+	for _, opt := range options {
+		opt(config)
+	}
+
+	limiter := &RateLimiter{
+		config:    config,
+		random:    rand.New(config.randSource),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: config.clock.Now(),
+		hosts:     make(map[string]*hostSlots),
+	}
+
+	if config.maxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, config.maxInFlight)
+	}
+
+	return limiter
+}
+
+// Acquire blocks until a request to the host is allowed. The returned release func must be called once the request is done.
+func (l *RateLimiter) Acquire(ctx context.Context, host string) (func(), error) {
+	_, release, err := l.acquireContext(ctx, host)
+
+	return release, err
+}
+
+// acquireContext marks the returned context so that nested layers using the same limiter do not acquire again.
+func (l *RateLimiter) acquireContext(ctx context.Context, host string) (context.Context, func(), error) {
+	key := rateLimiterContextKey{limiter: l}
+
+	if ctx.Value(key) != nil {
+		return ctx, func() {}, nil
+	}
+
+	host = strings.ToLower(host)
+
+	var releases []func()
+
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	// the global slot is taken last, a request waiting for its host must not hold up requests to other hosts
+	if l.config.maxInFlightHost > 0 {
+		slots := l.acquireHostSlots(host)
+
+		err := acquireSlot(ctx, slots.slots)
+		if err != nil {
+			l.releaseHostSlots(host, slots)
+			return ctx, nil, err
+		}
+
+		releases = append(releases, func() {
+			<-slots.slots
+			l.releaseHostSlots(host, slots)
+		})
+	}
+
+	err := l.waitForToken(ctx, host)
+	if err == nil {
+		err = l.wait(ctx, l.randomDelay())
+	}
+
+	if err == nil && l.inFlight != nil {
+		err = acquireSlot(ctx, l.inFlight)
+		if err == nil {
+			releases = append(releases, func() { <-l.inFlight })
+		}
+	}
+
+	if err != nil {
+		release()
+		return ctx, nil, err
+	}
+
+	return context.WithValue(ctx, key, true), release, nil
+}
+
+func (l *RateLimiter) acquireHostSlots(host string) *hostSlots {
+	l.lck.Lock()
+	defer l.lck.Unlock()
+
+	slots, ok := l.hosts[host]
+	if !ok {
+		slots = &hostSlots{slots: make(chan struct{}, l.config.maxInFlightHost)}
+		l.hosts[host] = slots
+	}
+
+	slots.users++
+
+	return slots
+}
+
+func (l *RateLimiter) releaseHostSlots(host string, slots *hostSlots) {
+	l.lck.Lock()
+	defer l.lck.Unlock()
+
+	slots.users--
+
+	if slots.users == 0 {
+		delete(l.hosts, host)
+	}
+}
+
+func (l *RateLimiter) waitForToken(ctx context.Context, host string) error {
+	for {
+		wait, ok := l.takeToken(host)
+		if ok {
+			return nil
+		}
+
+		err := l.wait(ctx, wait)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// takeToken refills the bucket of the host and takes a token. Otherwise it returns how long until the next token.
+func (l *RateLimiter) takeToken(host string) (time.Duration, bool) {
+	rate, ok := l.config.hostRates[host]
+	if !ok {
+		rate = l.config.hostRate
+	}
+
+	if rate.perSecond <= 0 {
+		return 0, true
+	}
+
+	burst := float64(rate.burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.lck.Lock()
+	defer l.lck.Unlock()
+
+	now := l.config.clock.Now()
+	l.sweepBuckets(now)
+
+	bucket, ok := l.buckets[host]
+	if !ok {
+		bucket = &tokenBucket{rate: rate, tokens: burst, last: now}
+		l.buckets[host] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * rate.perSecond
+	if bucket.tokens > burst {
+		bucket.tokens = burst
+	}
+
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - bucket.tokens) / rate.perSecond * float64(time.Second)), false
+}
+
+// sweepBuckets drops the buckets of hosts not requested long enough to refill completely. It expects the lock to be held.
+func (l *RateLimiter) sweepBuckets(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+
+	l.lastSweep = now
+
+	for host, bucket := range l.buckets {
+		burst := float64(bucket.rate.burst)
+		if burst < 1 {
+			burst = 1
+		}
+
+		if bucket.full(now, burst) {
+			delete(l.buckets, host)
+		}
+	}
+}
+
+func (l *RateLimiter) randomDelay() time.Duration {
+	if l.config.maxDelay <= 0 {
+		return 0
+	}
+
+	if l.config.maxDelay <= l.config.minDelay {
+		return l.config.minDelay
+	}
+
+	l.lck.Lock()
+	defer l.lck.Unlock()
+
+	return l.config.minDelay + time.Duration(l.random.Int63n(int64(l.config.maxDelay-l.config.minDelay)))
+}
+
+func (l *RateLimiter) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.config.clock.After(d):
+		return nil
+	}
+}
+
+func acquireSlot(ctx context.Context, slots chan struct{}) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case slots <- struct{}{}:
+		return nil
+	}
+}
+
+func requestHost(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+
+	return parsed.Hostname()
+}
@@ -0,0 +1,150 @@
+package helheim_go
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func acquireAsync(limiter *RateLimiter, ctx context.Context, host string) chan error {
+	acquired := make(chan error, 1)
+
+	go func() {
+		release, err := limiter.Acquire(ctx, host)
+		if err == nil {
+			release()
+		}
+
+		acquired <- err
+	}()
+
+	return acquired
+}
+
+func TestRateLimiterWaitsForTokens(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	limiter := NewRateLimiter(WithHostRate(1, 1), WithRateLimiterClock(clock))
+
+	release, err := limiter.Acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("expected the first request to pass right away: %v", err)
+	}
+
+	release()
+
+	acquired := acquireAsync(limiter, context.Background(), "example.com")
+
+	if wait := clock.fire(); wait != time.Second {
+		t.Fatalf("expected to wait one second for the next token, waited %s", wait)
+	}
+
+	if err := <-acquired; err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+}
+
+func TestRateLimiterRandomDelay(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	limiter := NewRateLimiter(
+		WithRandomDelay(100*time.Millisecond, 200*time.Millisecond),
+		WithRateLimiterClock(clock),
+		WithRateLimiterRandSource(rand.NewSource(7)),
+	)
+
+	expected := rand.New(rand.NewSource(7))
+
+	for i := 0; i < 3; i++ {
+		acquired := acquireAsync(limiter, context.Background(), "example.com")
+
+		delay := 100*time.Millisecond + time.Duration(expected.Int63n(int64(100*time.Millisecond)))
+
+		if wait := clock.fire(); wait != delay {
+			t.Fatalf("expected request %d to be delayed by %s, got %s", i, delay, wait)
+		}
+
+		if err := <-acquired; err != nil {
+			t.Fatalf("failed to acquire: %v", err)
+		}
+	}
+}
+
+func TestRateLimiterTakesGlobalSlotLast(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	limiter := NewRateLimiter(WithMaxInFlight(1), WithMaxInFlightPerHost(1), WithHostRateFor("slow.test", 1, 1), WithRateLimiterClock(clock))
+
+	release, err := limiter.Acquire(context.Background(), "slow.test")
+	if err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	release()
+
+	slow := acquireAsync(limiter, context.Background(), "slow.test")
+
+	// the slow request waits for its token now
+	timer := <-clock.timers
+
+	select {
+	case err := <-acquireAsync(limiter, context.Background(), "fast.test"):
+		if err != nil {
+			t.Fatalf("failed to acquire: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a request to another host to pass while the slow one waits for its token")
+	}
+
+	timer.ch <- clock.advance(timer.d)
+
+	if err := <-slow; err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+}
+
+func TestRateLimiterEvictsIdleHosts(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	limiter := NewRateLimiter(WithMaxInFlightPerHost(1), WithHostRate(10, 1), WithRateLimiterClock(clock))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release, err := limiter.Acquire(ctx, "a.test")
+	if err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	// a request giving up while it waits for its host slot releases it as well
+	waiting := acquireAsync(limiter, ctx, "a.test")
+	cancel()
+
+	if err := <-waiting; err != context.Canceled {
+		t.Fatalf("expected the waiting request to be canceled, got %v", err)
+	}
+
+	release()
+
+	limiter.lck.Lock()
+	hosts := len(limiter.hosts)
+	limiter.lck.Unlock()
+
+	if hosts != 0 {
+		t.Fatalf("expected the host slots to be dropped once unused, got %d", hosts)
+	}
+
+	clock.advance(2 * bucketSweepInterval)
+
+	release, err = limiter.Acquire(context.Background(), "b.test")
+	if err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+
+	release()
+
+	limiter.lck.Lock()
+	_, idle := limiter.buckets["a.test"]
+	_, active := limiter.buckets["b.test"]
+	limiter.lck.Unlock()
+
+	if idle || !active {
+		t.Errorf("expected only the bucket of the idle host to be dropped, got %v", limiter.buckets)
+	}
+}
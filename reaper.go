@@ -0,0 +1,160 @@
+package helheim_go
+
+import "time"
+
+const defaultReaperInterval = 1 * time.Minute
+
+type ReaperOption func(config *reaperConfig)
+
+type reaperConfig struct {
+	interval time.Duration
+	idleTTL  time.Duration
+	maxUses  int
+	onEvict  func(session Session, reason SessionCloseReason) bool
+}
+
+// WithReaperInterval sets how often the reaper checks the registered sessions. Defaults to one minute.
+func WithReaperInterval(interval time.Duration) ReaperOption {
+	return func(config *reaperConfig) {
+		config.interval = interval
+	}
+}
+
+// WithIdleTTL evicts sessions which were not used for longer than the given duration.
+func WithIdleTTL(ttl time.Duration) ReaperOption {
+	return func(config *reaperConfig) {
+		config.idleTTL = ttl
+	}
+}
+
+// WithMaxUses evicts sessions which already sent the given amount of requests.
+func WithMaxUses(maxUses int) ReaperOption {
+	return func(config *reaperConfig) {
+		config.maxUses = maxUses
+	}
+}
+
+// WithEvictionCallback registers a callback which is invoked before a session gets evicted, while it is still usable,
+// e.g. to save its cookies or headers. Returning false keeps the session.
+func WithEvictionCallback(onEvict func(session Session, reason SessionCloseReason) bool) ReaperOption {
+	return func(config *reaperConfig) {
+		config.onEvict = onEvict
+	}
+}
+
+// StartReaper starts a background goroutine which deletes idle or overused sessions. A running reaper is replaced.
+// Invalid options fail with a ValidationError and leave a running reaper untouched. A closed client fails with ErrClientClosed.
+func (c *client) StartReaper(options ...ReaperOption) error {
+	config := &reaperConfig{
+		interval: defaultReaperInterval,
+	}
+
+	for _, opt := range options {
+		opt(config)
+	}
+
+	err := config.validate()
+	if err != nil {
+		return err
+	}
+
+	c.StopReaper()
+
+	c.reaperLck.Lock()
+	defer c.reaperLck.Unlock()
+
+	// Close stops the reaper after marking the client closed, so checking under reaperLck cannot leak a reaper
+	if c.isClosed() {
+		return ErrClientClosed
+	}
+
+	stop := make(chan struct{})
+	c.reaperStop = stop
+
+	go c.runReaper(config, stop)
+
+	logFields(c.sessionLogger(), LevelInfo, "started session reaper", F(FieldOperation, "reap"), F("interval", config.interval))
+
+	return nil
+}
+
+func (c *client) StopReaper() {
+	c.reaperLck.Lock()
+	defer c.reaperLck.Unlock()
+
+	if c.reaperStop == nil {
+		return
+	}
+
+	close(c.reaperStop)
+	c.reaperStop = nil
+
+	logFields(c.sessionLogger(), LevelInfo, "stopped session reaper", F(FieldOperation, "reap"))
+}
+
+func (c *client) runReaper(config *reaperConfig, stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-c.clock().After(config.interval):
+			c.reap(config, now)
+		}
+	}
+}
+
+func (c *client) reap(config *reaperConfig, now time.Time) {
+	c.sessionsLck.RLock()
+	sessions := make([]*session, 0, len(c.sessions))
+	for _, s := range c.sessions {
+		sessions = append(sessions, s)
+	}
+	c.sessionsLck.RUnlock()
+
+	for _, s := range sessions {
+		info := s.info()
+
+		var reason SessionCloseReason
+
+		switch {
+		case config.idleTTL > 0 && now.Sub(info.LastUsedAt) > config.idleTTL:
+			reason = SessionCloseReasonIdle
+		case config.maxUses > 0 && info.UseCount >= config.maxUses:
+			reason = SessionCloseReasonMaxUses
+		default:
+			continue
+		}
+
+		if config.onEvict != nil && !config.onEvict(s, reason) {
+			logFields(c.sessionLogger(), LevelDebug, "eviction vetoed by callback", F(FieldOperation, "reap"), F(FieldSessionId, info.SessionId), F("reason", reason))
+			continue
+		}
+
+		err := s.close(reason)
+
+		if err != nil {
+			logFields(c.sessionLogger(), LevelError, "failed to evict session", F(FieldOperation, "reap"), F(FieldSessionId, info.SessionId), F(FieldError, err))
+			continue
+		}
+
+		logFields(c.sessionLogger(), LevelInfo, "evicted session", F(FieldOperation, "reap"), F(FieldSessionId, info.SessionId), F("reason", reason))
+	}
+}
+
+func (config *reaperConfig) validate() error {
+	validationErr := &ValidationError{}
+
+	if config.interval <= 0 {
+		validationErr.add("Reaper.Interval", "must be positive, got %s", config.interval)
+	}
+
+	if config.idleTTL < 0 {
+		validationErr.add("Reaper.IdleTTL", "must not be negative, got %s", config.idleTTL)
+	}
+
+	if config.maxUses < 0 {
+		validationErr.add("Reaper.MaxUses", "must not be negative, got %d", config.maxUses)
+	}
+
+	return validationErr.errOrNil()
+}
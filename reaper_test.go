@@ -0,0 +1,155 @@
+package helheim_go
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// reapOnce fires the reaper timer and waits until the reaper asked for the next one, i.e. finished the run.
+func reapOnce(clock *fakeClock) {
+	clock.fire()
+
+	next := <-clock.timers
+	clock.timers <- next
+}
+
+func startTestReaper(t *testing.T, c *client, options ...ReaperOption) {
+	t.Helper()
+
+	err := c.StartReaper(append([]ReaperOption{WithReaperInterval(time.Minute)}, options...)...)
+	if err != nil {
+		t.Fatalf("failed to start reaper: %v", err)
+	}
+
+	t.Cleanup(c.StopReaper)
+}
+
+func TestReaperEvictsIdleSessions(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	c := newTestClient(t, newFakeHelheim(), WithClock(clock))
+
+	idle, _ := c.NewSession(CreateSessionOptions{})
+	busy, _ := c.NewSession(CreateSessionOptions{})
+
+	var evicted []int
+	startTestReaper(t, c, WithIdleTTL(90*time.Second), WithEvictionCallback(func(session Session, reason SessionCloseReason) bool {
+		if session.IsClosed() {
+			t.Errorf("session %d: expected the callback to run before eviction", session.GetSessionId())
+		}
+
+		if reason != SessionCloseReasonIdle {
+			t.Errorf("session %d: expected reason %s, got %s", session.GetSessionId(), SessionCloseReasonIdle, reason)
+		}
+
+		evicted = append(evicted, session.GetSessionId())
+
+		return true
+	}))
+
+	reapOnce(clock)
+
+	_, err := busy.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	reapOnce(clock)
+
+	if len(evicted) != 1 || evicted[0] != idle.GetSessionId() {
+		t.Fatalf("expected only session %d to be evicted, got %v", idle.GetSessionId(), evicted)
+	}
+
+	if busy.IsClosed() {
+		t.Fatal("expected the recently used session to stay open")
+	}
+
+	_, err = idle.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+
+	var closedErr *SessionClosedError
+	if !errors.As(err, &closedErr) || closedErr.Reason != SessionCloseReasonIdle {
+		t.Fatalf("expected a SessionClosedError with reason %s, got %v", SessionCloseReasonIdle, err)
+	}
+
+	if _, err = c.GetSession(idle.GetSessionId()); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected the evicted session to be unregistered, got %v", err)
+	}
+}
+
+func TestReaperEvictsSessionsAtMaxUses(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	c := newTestClient(t, newFakeHelheim(), WithClock(clock))
+
+	s, _ := c.NewSession(CreateSessionOptions{})
+
+	startTestReaper(t, c, WithMaxUses(2))
+
+	_, _ = s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+	reapOnce(clock)
+
+	if s.IsClosed() {
+		t.Fatal("expected the session to stay open below max uses")
+	}
+
+	_, _ = s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+	reapOnce(clock)
+
+	_, err := s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+
+	var closedErr *SessionClosedError
+	if !errors.As(err, &closedErr) || closedErr.Reason != SessionCloseReasonMaxUses {
+		t.Fatalf("expected a SessionClosedError with reason %s, got %v", SessionCloseReasonMaxUses, err)
+	}
+}
+
+func TestReaperEvictionCallbackVetoes(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend, WithClock(clock))
+
+	s, _ := c.NewSession(CreateSessionOptions{})
+
+	startTestReaper(t, c, WithIdleTTL(time.Second), WithEvictionCallback(func(session Session, reason SessionCloseReason) bool {
+		return false
+	}))
+
+	reapOnce(clock)
+
+	if s.IsClosed() || backend.count("delete_session") != 0 {
+		t.Fatal("expected the vetoed session to stay open")
+	}
+}
+
+func TestReaperReopensSessionWhenDeleteFails(t *testing.T) {
+	clock := newFakeClock(time.Unix(1650000000, 0))
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend, WithClock(clock))
+
+	s, _ := c.NewSession(CreateSessionOptions{})
+
+	backend.lck.Lock()
+	backend.deleteErr = errors.New("delete failed")
+	backend.lck.Unlock()
+
+	startTestReaper(t, c, WithIdleTTL(time.Second))
+
+	reapOnce(clock)
+
+	if s.IsClosed() {
+		t.Fatal("expected the session to be reopened after the failed delete")
+	}
+
+	if _, err := c.GetSession(s.GetSessionId()); err != nil {
+		t.Fatalf("expected the session to stay registered, got %v", err)
+	}
+
+	backend.lck.Lock()
+	backend.deleteErr = nil
+	backend.lck.Unlock()
+
+	reapOnce(clock)
+
+	if !s.IsClosed() {
+		t.Fatal("expected the session to be evicted once deleting works again")
+	}
+}
@@ -0,0 +1,324 @@
+package helheim_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	redactedValue            = "***"
+	defaultMaxBodyLogLength  = 1024
+	redactionMinSecretLength = 4
+)
+
+var defaultRedactedHeaders = []string{"Cookie", "Set-Cookie", "Authorization", "Proxy-Authorization"}
+
+var proxyCredentialsPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^:/@\s"]+:)[^\s"]+@`)
+
+// hostPortCredentialsPattern matches proxies in the host:port:user:pass format.
+var hostPortCredentialsPattern = regexp.MustCompile(`(\b[a-zA-Z0-9.-]+:[0-9]{1,5}:[^:/@\s"]+:)[^\s",]+`)
+
+type RedactionOption func(config *redactionConfig)
+
+type redactionConfig struct {
+	secrets          []string
+	headers          []string
+	maxBodyLogLength int
+}
+
+// WithRedactedSecrets masks the given values wherever they appear in log output. The api key is always masked.
+func WithRedactedSecrets(secrets ...string) RedactionOption {
+	return func(config *redactionConfig) {
+		config.secrets = append(config.secrets, secrets...)
+	}
+}
+
+// WithRedactedHeaders masks the values of the given headers in addition to Cookie, Set-Cookie, Authorization and Proxy-Authorization.
+func WithRedactedHeaders(headers ...string) RedactionOption {
+	return func(config *redactionConfig) {
+		config.headers = append(config.headers, headers...)
+	}
+}
+
+// WithMaxBodyLogLength truncates logged response bodies to the given amount of bytes. Defaults to 1024. Zero disables the truncation.
+func WithMaxBodyLogLength(length int) RedactionOption {
+	return func(config *redactionConfig) {
+		config.maxBodyLogLength = length
+	}
+}
+
+type redactor struct {
+	secrets          []string
+	headers          map[string]bool
+	headerPattern    *regexp.Regexp
+	maxBodyLogLength int
+}
+
+func newRedactor(options ...RedactionOption) *redactor {
+	config := &redactionConfig{
+		headers:          append([]string{}, defaultRedactedHeaders...),
+		maxBodyLogLength: defaultMaxBodyLogLength,
+	}
+
+	for _, opt := range options {
+		opt(config)
+	}
+
+	r := &redactor{
+		headers:          make(map[string]bool, len(config.headers)),
+		maxBodyLogLength: config.maxBodyLogLength,
+	}
+
+	for _, secret := range config.secrets {
+		// masking very short values would garble the whole log line
+		if len(secret) >= redactionMinSecretLength {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+
+	names := make([]string, 0, len(config.headers))
+
+	for _, header := range config.headers {
+		r.headers[strings.ToLower(header)] = true
+		names = append(names, regexp.QuoteMeta(header))
+	}
+
+	// "Name: value" masks up to the end of the line, "name=value" only the value itself
+	r.headerPattern = regexp.MustCompile(`(?i)(\b(?:` + strings.Join(names, "|") + `)\b)(:[ \t]*[^\r\n]+|=(?:"[^"]*"|[^\s"]+))`)
+
+	return r
+}
+
+func (r *redactor) redact(s string) string {
+	trimmed := strings.TrimSpace(s)
+
+	if strings.HasPrefix(trimmed, "{") {
+		var payload interface{}
+
+		if err := json.Unmarshal([]byte(trimmed), &payload); err == nil {
+			redacted, err := json.Marshal(r.redactJSON(payload))
+
+			if err == nil {
+				return string(redacted)
+			}
+		}
+	}
+
+	return r.redactText(s)
+}
+
+func (r *redactor) redactText(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+
+	s = r.headerPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := r.headerPattern.FindStringSubmatch(match)
+
+		if strings.HasPrefix(groups[2], ":") {
+			return groups[1] + ": " + redactedValue
+		}
+
+		return groups[1] + "=" + redactedValue
+	})
+
+	return redactProxyCredentials(s)
+}
+
+func redactProxyCredentials(s string) string {
+	s = proxyCredentialsPattern.ReplaceAllString(s, "${1}"+redactedValue+"@")
+
+	return hostPortCredentialsPattern.ReplaceAllString(s, "${1}"+redactedValue)
+}
+
+// redactField masks the value of a structured log field. The field key is treated like a JSON key,
+// e.g. the value of a cookies or authorization field is masked entirely.
+func (r *redactor) redactField(field Field) Field {
+	switch v := field.Value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Duration, time.Time, Level:
+		return field
+	case string:
+		field.Value = r.redactKeyValue(field.Key, v)
+	case error:
+		field.Value = r.redactKeyValue(field.Key, v.Error())
+	case fmt.Stringer:
+		field.Value = r.redactKeyValue(field.Key, v.String())
+	default:
+		var payload interface{}
+
+		encoded, err := json.Marshal(v)
+		if err == nil {
+			err = json.Unmarshal(encoded, &payload)
+		}
+
+		if err != nil {
+			field.Value = r.redactKeyValue(field.Key, fmt.Sprint(v))
+			return field
+		}
+
+		field.Value = r.redactKeyValue(field.Key, payload)
+	}
+
+	return field
+}
+
+func (r *redactor) redactKeyValue(key string, value interface{}) interface{} {
+	if s, ok := value.(string); ok && strings.HasPrefix(strings.TrimSpace(s), "{") {
+		return r.redact(s)
+	}
+
+	redacted := r.redactJSON(map[string]interface{}{key: value})
+
+	return redacted.(map[string]interface{})[key]
+}
+
+func (r *redactor) redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if r.headers[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+
+			switch strings.ToLower(key) {
+			case "cookies":
+				v[key] = redactCookies(child)
+			case "headers":
+				v[key] = r.redactHeaders(child)
+			case "body", "content":
+				v[key] = r.truncate(child)
+			default:
+				v[key] = r.redactJSON(child)
+			}
+		}
+
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.redactJSON(child)
+		}
+
+		return v
+	case string:
+		return r.redactText(v)
+	default:
+		return v
+	}
+}
+
+func redactCookies(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name := range v {
+			v[name] = redactedValue
+		}
+	case []interface{}:
+		for _, cookie := range v {
+			if c, ok := cookie.(map[string]interface{}); ok {
+				if _, ok := c["value"]; ok {
+					c["value"] = redactedValue
+				}
+			}
+		}
+	}
+
+	return value
+}
+
+func (r *redactor) redactHeaders(value interface{}) interface{} {
+	headers, ok := value.(map[string]interface{})
+
+	if !ok {
+		return r.redactJSON(value)
+	}
+
+	for name, headerValue := range headers {
+		if r.headers[strings.ToLower(name)] {
+			headers[name] = redactedValue
+			continue
+		}
+
+		headers[name] = r.redactJSON(headerValue)
+	}
+
+	return headers
+}
+
+func (r *redactor) truncate(value interface{}) interface{} {
+	s, ok := value.(string)
+
+	if !ok {
+		return r.redactJSON(value)
+	}
+
+	s = r.redactText(s)
+
+	if r.maxBodyLogLength <= 0 || len(s) <= r.maxBodyLogLength {
+		return s
+	}
+
+	// cut before the rune crossing the limit so the truncated body stays valid utf-8
+	cut := r.maxBodyLogLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s...(%d bytes truncated)", s[:cut], len(s)-cut)
+}
+
+type redactingLogger struct {
+	logger   Logger
+	redactor *redactor
+}
+
+// NewRedactingLogger masks secrets, proxy credentials, cookie values and sensitive headers before passing log entries to the given logger.
+// Clients created with NewClientWithOptions wrap their logger automatically.
+func NewRedactingLogger(logger Logger, options ...RedactionOption) Logger {
+	return newRedactingLogger(logger, newRedactor(options...))
+}
+
+func newRedactingLogger(logger Logger, redactor *redactor) Logger {
+	if logger == nil {
+		logger = NewNoopLogger()
+	}
+
+	if l, ok := logger.(*redactingLogger); ok {
+		logger = l.logger
+	}
+
+	return &redactingLogger{
+		logger:   logger,
+		redactor: redactor,
+	}
+}
+
+func (l *redactingLogger) Debug(format string, args ...interface{}) {
+	l.logger.Debug("%s", l.redactor.redact(fmt.Sprintf(format, args...)))
+}
+
+func (l *redactingLogger) Info(format string, args ...interface{}) {
+	l.logger.Info("%s", l.redactor.redact(fmt.Sprintf(format, args...)))
+}
+
+func (l *redactingLogger) Warn(format string, args ...interface{}) {
+	l.logger.Warn("%s", l.redactor.redact(fmt.Sprintf(format, args...)))
+}
+
+func (l *redactingLogger) Error(format string, args ...interface{}) {
+	l.logger.Error("%s", l.redactor.redact(fmt.Sprintf(format, args...)))
+}
+
+func (l *redactingLogger) Log(level Level, msg string, fields ...Field) {
+	redacted := make([]Field, 0, len(fields))
+
+	for _, field := range fields {
+		redacted = append(redacted, l.redactor.redactField(field))
+	}
+
+	logFields(l.logger, level, l.redactor.redact(msg), redacted...)
+}
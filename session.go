@@ -1,123 +1,350 @@
 package helheim_go
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
 type Session interface {
 	Delete() error
-	Debug(state int) (interface{}, error)
+	Debug(level DebugLevel) (*DebugResponse, error)
+	DebugState() DebugLevel
 	Request(options RequestOptions) (*RequestResponse, error)
 	Wokou(browser string) (*WokouResponse, error)
 	SetProxy(proxy string) (*SetProxyResponse, error)
 	SetHeaders(headers map[string]string) (*SetHeadersResponse, error)
 	SetCookie(cookie SessionCookie) (*ModifyCookiesResponse, error)
 	DelCookie(cookieName string) (*ModifyCookiesResponse, error)
-	SetKasada(options KasadaOptions) (interface{}, error)
-	SetKasadaHooks(options KasadaHooksOptions) (interface{}, error)
+	SetKasada(options KasadaOptions) (*SetKasadaResponse, error)
+	SetKasadaHooks(options KasadaHooksOptions) (*SetKasadaHooksResponse, error)
 	GetGoHttpCookies() []*http.Cookie
 	GetSessionId() int
+	GetProxy() string
 	GetHeaders() map[string]string
 	GetCookies() []SessionCookie
+	GetOptions() CreateSessionOptions
+	GetCreatedAt() time.Time
+	GetLastUsedAt() time.Time
+	GetUseCount() int
+	GetLabel() string
+	GetUsage() Usage
+	IsClosed() bool
+}
+
+// ContextSession is implemented by the sessions of the client. The context is passed on to the rate limiter and the
+// tracing spans of the operation. Assert it on a Session to use it:
+//
+//	resp, err := s.(helheim_go.ContextSession).RequestContext(ctx, options)
+type ContextSession interface {
+	Session
+	DebugContext(ctx context.Context, level DebugLevel) (*DebugResponse, error)
+	RequestContext(ctx context.Context, options RequestOptions) (*RequestResponse, error)
+	WokouContext(ctx context.Context, browser string) (*WokouResponse, error)
+	SetProxyContext(ctx context.Context, proxy string) (*SetProxyResponse, error)
+	SetHeadersContext(ctx context.Context, headers map[string]string) (*SetHeadersResponse, error)
 }
 
 type session struct {
 	logger         Logger
+	clock          Clock
 	helheim        Helheim
 	helheimSession SessionResponse
+	options        CreateSessionOptions
 	sessionId      int
 	headers        map[string]string
 	cookies        []SessionCookie
+	createdAt      time.Time
+	stateLck       sync.Mutex
+	lastUsedAt     time.Time
+	label          string
+	usage          Usage
+	closed         bool
+	closeReason    SessionCloseReason
+	debugLevel     DebugLevel
+	limiter        *RateLimiter
+	proxyLck       sync.Mutex
+	proxy          sessionProxy
+	beforeRequest  func() error
+	onClose        func(info SessionInfo, reason SessionCloseReason)
 }
 
-func newSession(logger Logger, helheim Helheim, options CreateSessionOptions) (Session, error) {
+func newSession(logger Logger, clock Clock, helheim Helheim, options CreateSessionOptions, limiter *RateLimiter, beforeRequest func() error, onClose func(info SessionInfo, reason SessionCloseReason)) (*session, error) {
 	helheimSession, err := helheim.CreateSession(options)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if logger == nil {
-		logger = NewNoopLogger()
+	logger = withComponent(logger, ComponentSession)
+
+	now := clock.Now()
+
+	debugLevel := DebugLevelOff
+	if options.Debug != nil && *options.Debug {
+		debugLevel = DebugLevelOn
 	}
 
 	return &session{
 		logger:         logger,
+		clock:          clock,
 		helheim:        helheim,
 		helheimSession: *helheimSession,
+		options:        options,
+		label:          options.Label,
+		usage:          Usage{Errors: make(map[ErrorClass]int64)},
 		sessionId:      helheimSession.SessionId,
 		headers:        helheimSession.Headers,
 		cookies:        helheimSession.Cookies,
+		createdAt:      now,
+		lastUsedAt:     now,
+		debugLevel:     debugLevel,
+		limiter:        limiter,
+		beforeRequest:  beforeRequest,
+		onClose:        onClose,
 	}, nil
 }
 
 func (s *session) Request(options RequestOptions) (*RequestResponse, error) {
-	resp, err := s.helheim.Request(s.GetSessionId(), options)
+	return s.RequestContext(context.Background(), options)
+}
+
+func (s *session) RequestContext(ctx context.Context, options RequestOptions) (*RequestResponse, error) {
+	// the license guard runs first so refused requests are not counted as uses
+	if s.beforeRequest != nil {
+		err := s.beforeRequest()
+		if err != nil {
+			s.recordError(err)
+			return nil, err
+		}
+	}
 
+	err := s.acquire(true)
 	if err != nil {
 		return nil, err
 	}
 
-	for key, value := range resp.Session.Headers {
-		s.headers[key] = value
+	if s.limiter != nil {
+		var release func()
+
+		ctx, release, err = s.limiter.acquireContext(ctx, requestHost(options.Url))
+		if err != nil {
+			s.recordError(err)
+			return nil, err
+		}
+
+		defer release()
 	}
 
-	s.cookies = resp.Session.Cookies
+	proxy, err := s.requestProxy(ctx)
+	if err != nil {
+		s.recordError(err)
+		return nil, err
+	}
+
+	var resp *RequestResponse
+
+	if h, ok := s.helheim.(contextHelheim); ok {
+		resp, err = h.requestContext(ctx, s.GetSessionId(), options)
+	} else {
+		resp, err = s.helheim.Request(s.GetSessionId(), options)
+	}
+
+	s.reportProxyResult(proxy, resp, err)
+
+	if err != nil {
+		s.recordError(err)
+		return nil, err
+	}
+
+	s.recordResponse(resp)
 
 	return resp, nil
 }
 
 func (s *session) Wokou(browser string) (*WokouResponse, error) {
+	return s.WokouContext(context.Background(), browser)
+}
+
+func (s *session) WokouContext(ctx context.Context, browser string) (*WokouResponse, error) {
+	err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if h, ok := s.helheim.(contextHelheim); ok {
+		return h.wokouContext(ctx, s.GetSessionId(), browser)
+	}
+
 	return s.helheim.Wokou(s.GetSessionId(), browser)
 }
 
 func (s *session) SetProxy(proxy string) (*SetProxyResponse, error) {
-	return s.helheim.SetProxy(s.GetSessionId(), proxy)
+	return s.SetProxyContext(context.Background(), proxy)
+}
+
+func (s *session) SetProxyContext(ctx context.Context, proxy string) (*SetProxyResponse, error) {
+	if proxy != "" {
+		normalized, err := NormalizeProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		proxy = normalized
+	}
+
+	err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setProxy(ctx, proxy)
 }
 
 func (s *session) SetHeaders(headers map[string]string) (*SetHeadersResponse, error) {
-	for key, value := range headers {
-		s.headers[key] = value
+	return s.SetHeadersContext(context.Background(), headers)
+}
+
+func (s *session) SetHeadersContext(ctx context.Context, headers map[string]string) (*SetHeadersResponse, error) {
+	err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mergeHeaders(headers)
+
+	if h, ok := s.helheim.(contextHelheim); ok {
+		return h.setHeadersContext(ctx, s.GetSessionId(), headers)
 	}
 
 	return s.helheim.SetHeaders(s.GetSessionId(), headers)
 }
 
+// setProfileHeaders sets the headers of the profile in its header order.
+func (s *session) setProfileHeaders(profile BrowserProfile) (*SetHeadersResponse, error) {
+	err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := profile.SessionHeaders()
+	s.mergeHeaders(headers)
+
+	return setOrderedHeaders(s.helheim, s.GetSessionId(), headers, profile.HeaderOrder)
+}
+
+func (s *session) mergeHeaders(headers map[string]string) {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	for key, value := range headers {
+		s.headers[key] = value
+	}
+}
+
 func (s *session) SetCookie(cookie SessionCookie) (*ModifyCookiesResponse, error) {
+	err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := s.helheim.SetCookie(s.GetSessionId(), cookie)
 
 	if err != nil {
 		return nil, err
 	}
 
-	s.cookies = resp.Cookies
+	s.setCookies(resp.Cookies)
 
 	return resp, nil
 }
 
 func (s *session) DelCookie(cookieName string) (*ModifyCookiesResponse, error) {
+	err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := s.helheim.DelCookie(s.GetSessionId(), cookieName)
 
 	if err != nil {
 		return nil, err
 	}
 
-	s.cookies = resp.Cookies
+	s.setCookies(resp.Cookies)
 
 	return resp, nil
 }
 
-func (s *session) Debug(state int) (interface{}, error) {
-	return s.helheim.Debug(s.GetSessionId(), state)
+func (s *session) Debug(level DebugLevel) (*DebugResponse, error) {
+	return s.DebugContext(context.Background(), level)
+}
+
+func (s *session) DebugContext(ctx context.Context, level DebugLevel) (*DebugResponse, error) {
+	err := level.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *DebugResponse
+
+	if h, ok := s.helheim.(contextHelheim); ok {
+		resp, err = h.debugContext(ctx, s.GetSessionId(), level)
+	} else {
+		resp, err = s.helheim.Debug(s.GetSessionId(), level)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.stateLck.Lock()
+	s.debugLevel = level
+	s.stateLck.Unlock()
+
+	return resp, nil
 }
 
-func (s *session) SetKasada(options KasadaOptions) (interface{}, error) {
+// DebugState returns the debug level last applied successfully to the helheim session.
+func (s *session) DebugState() DebugLevel {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	return s.debugLevel
+}
+
+func (s *session) SetKasada(options KasadaOptions) (*SetKasadaResponse, error) {
+	err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
 	return s.helheim.SetKasada(s.GetSessionId(), options)
 }
 
-func (s *session) SetKasadaHooks(options KasadaHooksOptions) (interface{}, error) {
+func (s *session) SetKasadaHooks(options KasadaHooksOptions) (*SetKasadaHooksResponse, error) {
+	err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+
 	return s.helheim.SetKasadaHooks(s.GetSessionId(), options)
 }
 
@@ -125,8 +352,17 @@ func (s *session) GetSessionId() int {
 	return s.sessionId
 }
 
+// GetHeaders returns a copy of the headers helheim sends with every request of the session.
 func (s *session) GetHeaders() map[string]string {
-	return s.headers
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	headers := make(map[string]string, len(s.headers))
+	for key, value := range s.headers {
+		headers[key] = value
+	}
+
+	return headers
 }
 
 func (s *session) GetGoHttpCookies() []*http.Cookie {
@@ -146,18 +382,179 @@ func (s *session) GetGoHttpCookies() []*http.Cookie {
 }
 
 func (s *session) GetCookies() []SessionCookie {
-	return s.cookies
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	return append([]SessionCookie(nil), s.cookies...)
+}
+
+func (s *session) setCookies(cookies []SessionCookie) {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	s.cookies = cookies
 }
 
 func (s *session) Delete() error {
+	return s.close(SessionCloseReasonDeleted)
+}
+
+func (s *session) GetOptions() CreateSessionOptions {
+	return s.options
+}
+
+func (s *session) GetCreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *session) GetLastUsedAt() time.Time {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	return s.lastUsedAt
+}
+
+func (s *session) GetUseCount() int {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	return int(s.usage.Requests)
+}
+
+func (s *session) GetLabel() string {
+	return s.label
+}
+
+func (s *session) GetUsage() Usage {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	return s.usage.copy()
+}
+
+func (s *session) recordError(err error) {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	s.usage.Errors[classifyError(err)]++
+}
+
+// recordResponse counts the received bytes and treats a changed clearance cookie as a solved challenge.
+// Afterwards the headers and cookies of the session are taken over from the response.
+func (s *session) recordResponse(resp *RequestResponse) {
+	received := len(resp.Response.Body)
+	if resp.Response.Content != "" {
+		received = len(resp.Response.Content)
+	}
+
+	solved := clearanceCookie(resp.Session.Cookies)
+
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	s.usage.BytesReceived += int64(received)
+
+	if solved != "" && solved != clearanceCookie(s.cookies) {
+		s.usage.ChallengesSolved++
+	}
+
+	for key, value := range resp.Session.Headers {
+		s.headers[key] = value
+	}
+
+	s.cookies = resp.Session.Cookies
+}
+
+func (s *session) IsClosed() bool {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	return s.closed
+}
+
+func (s *session) info() SessionInfo {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	return SessionInfo{
+		SessionId:  s.sessionId,
+		CreatedAt:  s.createdAt,
+		LastUsedAt: s.lastUsedAt,
+		UseCount:   int(s.usage.Requests),
+		Label:      s.label,
+		Options:    s.options,
+	}
+}
+
+// acquire fails with a SessionClosedError once the session got deleted or evicted and counts it as error.
+// Otherwise it records the usage.
+func (s *session) acquire(countUse bool) error {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	if s.closed {
+		err := &SessionClosedError{SessionId: s.sessionId, Reason: s.closeReason}
+		s.usage.Errors[classifyError(err)]++
+
+		return err
+	}
+
+	s.lastUsedAt = s.clock.Now()
+
+	if countUse {
+		s.usage.Requests++
+	}
+
+	return nil
+}
+
+func (s *session) close(reason SessionCloseReason) error {
+	if !s.markClosed(reason) {
+		return &SessionClosedError{SessionId: s.sessionId, Reason: s.closeReason}
+	}
+
+	return s.release(reason)
+}
+
+// markClosed refuses any further work on the session. It reports false when the session was closed already.
+func (s *session) markClosed(reason SessionCloseReason) bool {
+	s.stateLck.Lock()
+	defer s.stateLck.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.closed = true
+	s.closeReason = reason
+
+	return true
+}
+
+// release deletes the helheim session of a session marked as closed. A failed deletion reopens the session
+// unless the client got closed, as a closed client must not hand out working sessions anymore.
+func (s *session) release(reason SessionCloseReason) error {
 	resp, err := s.helheim.DeleteSession(s.GetSessionId())
 
+	if err == nil && resp != nil && resp.Error != false {
+		err = fmt.Errorf("failed to delete session %d", s.GetSessionId())
+	}
+
 	if err != nil {
+		if reason != SessionCloseReasonClientClosed {
+			s.stateLck.Lock()
+			s.closed = false
+			s.closeReason = ""
+			s.stateLck.Unlock()
+		}
+
 		return err
 	}
 
-	if resp != nil && resp.Error != false {
-		return fmt.Errorf("failed to delete session %d", s.GetSessionId())
+	s.releaseProxy()
+
+	if s.onClose != nil {
+		s.onClose(s.info(), reason)
 	}
 
 	return nil
@@ -0,0 +1,285 @@
+package helheim_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type BrowserName string
+
+const (
+	BrowserChrome  BrowserName = "chrome"
+	BrowserFirefox BrowserName = "firefox"
+)
+
+type Platform string
+
+const (
+	PlatformWindows Platform = "windows"
+	PlatformDarwin  Platform = "darwin"
+	PlatformLinux   Platform = "linux"
+	PlatformAndroid Platform = "android"
+	PlatformIOS     Platform = "ios"
+)
+
+type CaptchaProvider string
+
+const (
+	CaptchaProviderVanaheim       CaptchaProvider = "vanaheim"
+	CaptchaProvider2Captcha       CaptchaProvider = "2captcha"
+	CaptchaProviderAntiCaptcha    CaptchaProvider = "anticaptcha"
+	CaptchaProviderCapMonster     CaptchaProvider = "capmonster"
+	CaptchaProviderDeathByCaptcha CaptchaProvider = "deathbycaptcha"
+	CaptchaProvider9kw            CaptchaProvider = "9kw"
+	CaptchaProviderReturn         CaptchaProvider = "return"
+)
+
+type Interpreter string
+
+const (
+	InterpreterJs2Py      Interpreter = "js2py"
+	InterpreterNodeJs     Interpreter = "nodejs"
+	InterpreterNative     Interpreter = "native"
+	InterpreterV8         Interpreter = "v8"
+	InterpreterChakraCore Interpreter = "chakracore"
+)
+
+var supportedInterpreters = []Interpreter{InterpreterJs2Py, InterpreterNodeJs, InterpreterNative, InterpreterV8, InterpreterChakraCore}
+
+var supportedBrowsers = []BrowserName{BrowserChrome, BrowserFirefox}
+
+var supportedPlatforms = []Platform{PlatformWindows, PlatformDarwin, PlatformLinux, PlatformAndroid, PlatformIOS}
+
+// captchaProviderCredentials lists the credentials every supported provider needs. An empty entry means none.
+var captchaProviderCredentials = map[CaptchaProvider][]string{
+	CaptchaProviderVanaheim:       nil,
+	CaptchaProvider2Captcha:       {"api_key"},
+	CaptchaProviderAntiCaptcha:    {"api_key"},
+	CaptchaProviderCapMonster:     {"api_key"},
+	CaptchaProviderDeathByCaptcha: {"username", "password"},
+	CaptchaProvider9kw:            {"api_key"},
+	CaptchaProviderReturn:         nil,
+}
+
+type CreateSessionOptions struct {
+	Browser BrowserOptions `json:"browser"`
+	Captcha CaptchaOptions `json:"captcha"`
+	// Label tags the session in usage reports. It is not sent to helheim.
+	Label string `json:"-"`
+	// Profile overrides Browser and sets the profile headers right after the session got created.
+	Profile *BrowserProfile `json:"-"`
+	// Interpreter selects the javascript interpreter solving the challenges.
+	Interpreter Interpreter `json:"interpreter,omitempty"`
+	// Delay overrides the delay before a challenge answer is submitted. It is sent in seconds.
+	Delay       *time.Duration `json:"-"`
+	AllowBrotli *bool          `json:"allow_brotli,omitempty"`
+	// Debug enables the helheim debug output from the start. See Session.Debug to toggle it later.
+	Debug *bool `json:"debug,omitempty"`
+	// SolveDepth limits how many challenges in a row are solved for one request.
+	SolveDepth *int `json:"solveDepth,omitempty"`
+	// Extra is merged into the session options for settings without a dedicated field. helheim request hooks are
+	// Python callables which can not be carried over JSON, so there are no hook fields. Pass hook names the helheim
+	// runtime resolves on its side via Extra instead.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// isEmpty reports whether no backend relevant option is set. The label is ignored.
+func (o CreateSessionOptions) isEmpty() bool {
+	return o.Browser == BrowserOptions{} && o.Captcha.isEmpty() && o.Profile == nil &&
+		o.Interpreter == "" && o.Delay == nil && o.AllowBrotli == nil && o.Debug == nil && o.SolveDepth == nil &&
+		len(o.Extra) == 0
+}
+
+// MarshalJSON sends the delay in seconds and merges the extra options next to the dedicated fields.
+func (o CreateSessionOptions) MarshalJSON() ([]byte, error) {
+	type plain CreateSessionOptions
+
+	encoded, err := json.Marshal(plain(o))
+	if err != nil || (o.Delay == nil && len(o.Extra) == 0) {
+		return encoded, err
+	}
+
+	fields := make(map[string]interface{})
+
+	err = json.Unmarshal(encoded, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range o.Extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+
+	if o.Delay != nil {
+		fields["delay"] = o.Delay.Seconds()
+	}
+
+	return json.Marshal(fields)
+}
+
+// Validate checks the options before they are sent to helheim. Empty browser, platform and provider values are
+// left to the helheim defaults.
+func (o CreateSessionOptions) Validate() error {
+	validationErr := &ValidationError{}
+
+	browser := o.Browser
+	if o.Profile != nil {
+		browser = o.Profile.Browser
+	}
+
+	browser.validate(validationErr, "Browser")
+	o.Captcha.validate(validationErr, "Captcha")
+
+	if o.Interpreter != "" && !containsInterpreter(supportedInterpreters, o.Interpreter) {
+		validationErr.add("Interpreter", "unsupported interpreter %q", o.Interpreter)
+	}
+
+	if o.Delay != nil && *o.Delay < 0 {
+		validationErr.add("Delay", "must not be negative")
+	}
+
+	if o.SolveDepth != nil && *o.SolveDepth < 1 {
+		validationErr.add("SolveDepth", "must be at least 1")
+	}
+
+	for _, key := range []string{"browser", "captcha", "interpreter", "delay", "allow_brotli", "debug", "solveDepth"} {
+		if _, ok := o.Extra[key]; ok {
+			validationErr.add(fmt.Sprintf("Extra[%s]", key), "use the dedicated field instead")
+		}
+	}
+
+	return validationErr.errOrNil()
+}
+
+type BrowserOptions struct {
+	Browser  BrowserName `json:"browser"`
+	Mobile   bool        `json:"mobile"`
+	Platform Platform    `json:"platform"`
+}
+
+func (o BrowserOptions) validate(validationErr *ValidationError, field string) {
+	if o.Browser != "" && !containsBrowser(supportedBrowsers, o.Browser) {
+		validationErr.add(field+".Browser", "unsupported browser %q", o.Browser)
+	}
+
+	if o.Platform == "" {
+		return
+	}
+
+	if !containsPlatform(supportedPlatforms, o.Platform) {
+		validationErr.add(field+".Platform", "unsupported platform %q", o.Platform)
+		return
+	}
+
+	mobilePlatform := o.Platform == PlatformAndroid || o.Platform == PlatformIOS
+
+	if o.Mobile != mobilePlatform {
+		validationErr.add(field+".Mobile", "mobile is %t but platform %q is a %s platform", o.Mobile, o.Platform, platformKind(mobilePlatform))
+	}
+}
+
+type CaptchaOptions struct {
+	Provider CaptchaProvider `json:"provider"`
+	// ApiKey is sent as api_key and required by the 2captcha, anticaptcha, capmonster and 9kw providers.
+	ApiKey string `json:"api_key,omitempty"`
+	// Extra holds further provider options like username and password of deathbycaptcha.
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (o CaptchaOptions) isEmpty() bool {
+	return o.Provider == "" && o.ApiKey == "" && len(o.Extra) == 0
+}
+
+// MarshalJSON merges the extra options into the captcha object next to provider and api_key.
+func (o CaptchaOptions) MarshalJSON() ([]byte, error) {
+	captcha := make(map[string]interface{}, len(o.Extra)+2)
+
+	for key, value := range o.Extra {
+		captcha[key] = value
+	}
+
+	captcha["provider"] = o.Provider
+
+	if o.ApiKey != "" {
+		captcha["api_key"] = o.ApiKey
+	}
+
+	return json.Marshal(captcha)
+}
+
+func (o CaptchaOptions) validate(validationErr *ValidationError, field string) {
+	for _, key := range []string{"provider", "api_key"} {
+		if _, ok := o.Extra[key]; ok {
+			validationErr.add(fmt.Sprintf("%s.Extra[%s]", field, key), "use the dedicated field instead")
+		}
+	}
+
+	if o.Provider == "" {
+		if o.ApiKey != "" || len(o.Extra) > 0 {
+			validationErr.add(field+".Provider", "provider is required when captcha credentials are set")
+		}
+
+		return
+	}
+
+	credentials, ok := captchaProviderCredentials[o.Provider]
+	if !ok {
+		validationErr.add(field+".Provider", "unsupported captcha provider %q", o.Provider)
+		return
+	}
+
+	for _, credential := range credentials {
+		if credential == "api_key" {
+			if o.ApiKey == "" {
+				validationErr.add(field+".ApiKey", "api key is required for captcha provider %q", o.Provider)
+			}
+
+			continue
+		}
+
+		if value, ok := o.Extra[credential]; !ok || value == "" {
+			validationErr.add(fmt.Sprintf("%s.Extra[%s]", field, credential), "required for captcha provider %q", o.Provider)
+		}
+	}
+}
+
+func containsBrowser(browsers []BrowserName, browser BrowserName) bool {
+	for _, b := range browsers {
+		if b == browser {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsPlatform(platforms []Platform, platform Platform) bool {
+	for _, p := range platforms {
+		if p == platform {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsInterpreter(interpreters []Interpreter, interpreter Interpreter) bool {
+	for _, i := range interpreters {
+		if i == interpreter {
+			return true
+		}
+	}
+
+	return false
+}
+
+func platformKind(mobile bool) string {
+	if mobile {
+		return "mobile"
+	}
+
+	return "desktop"
+}
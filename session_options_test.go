@@ -0,0 +1,61 @@
+package helheim_go
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bogdanfinn/helheim-go/ptr"
+)
+
+func TestCreateSessionOptionsValidateBrowser(t *testing.T) {
+	tests := []struct {
+		browser BrowserOptions
+		valid   bool
+	}{
+		{browser: BrowserOptions{Browser: BrowserChrome, Platform: PlatformWindows}, valid: true},
+		{browser: BrowserOptions{Browser: BrowserFirefox, Mobile: true, Platform: PlatformAndroid}, valid: true},
+		{browser: BrowserOptions{Browser: "safari", Platform: PlatformDarwin}, valid: false},
+		{browser: BrowserOptions{Browser: BrowserChrome, Platform: PlatformIOS}, valid: false},
+	}
+
+	for _, test := range tests {
+		err := CreateSessionOptions{Browser: test.browser}.Validate()
+
+		if (err == nil) != test.valid {
+			t.Errorf("expected valid %t for %+v, got %v", test.valid, test.browser, err)
+		}
+
+		if err != nil && !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("expected a validation error for %+v, got %v", test.browser, err)
+		}
+	}
+}
+
+func TestCreateSessionOptionsMarshalJSON(t *testing.T) {
+	options := CreateSessionOptions{
+		Delay:      ptr.Duration(1500 * time.Millisecond),
+		Debug:      ptr.Bool(true),
+		SolveDepth: ptr.Int(2),
+		Extra:      map[string]interface{}{"custom": "value", "debug": false},
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		t.Fatalf("failed to marshal options: %v", err)
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal options: %v", err)
+	}
+
+	expected := map[string]interface{}{"delay": 1.5, "debug": true, "solveDepth": 2.0, "custom": "value"}
+
+	for key, value := range expected {
+		if fields[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, fields[key])
+		}
+	}
+}
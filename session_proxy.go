@@ -0,0 +1,216 @@
+package helheim_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var defaultProxyBlockStatus = []int{http.StatusForbidden, http.StatusProxyAuthRequired, http.StatusTooManyRequests}
+
+// sessionProxy is the proxy of a session. A proxy taken from the pool is rotated after a failure, a proxy set by the
+// user stays until the user replaces it.
+type sessionProxy struct {
+	pool        *ProxyPool
+	blockStatus []int
+	url         string
+	fromPool    bool
+}
+
+func (p sessionProxy) isBlockStatus(statusCode int) bool {
+	for _, blockStatus := range p.blockStatus {
+		if blockStatus == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func proxyBlockStatusOrDefault(blockStatusCodes []int) []int {
+	if len(blockStatusCodes) == 0 {
+		return defaultProxyBlockStatus
+	}
+
+	return blockStatusCodes
+}
+
+// GetProxy returns the proxy currently applied to the helheim session.
+func (s *session) GetProxy() string {
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	return s.proxy.url
+}
+
+// useProxyPool takes the proxy of the session from the pool with the next request. A proxy taken from another pool
+// before is given back, a proxy set by the user is kept.
+func (s *session) useProxyPool(pool *ProxyPool, blockStatusCodes []int) {
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	if s.proxy.fromPool {
+		s.proxy.pool.Release(s.sessionId)
+		s.proxy.url = ""
+		s.proxy.fromPool = false
+	}
+
+	s.proxy.pool = pool
+	s.proxy.blockStatus = proxyBlockStatusOrDefault(blockStatusCodes)
+}
+
+func (s *session) setProxy(ctx context.Context, proxy string) (*SetProxyResponse, error) {
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	// without a proxy the session falls back to its pool
+	if proxy == "" && s.proxy.pool != nil {
+		if s.proxy.fromPool {
+			s.proxy.pool.Release(s.sessionId)
+		}
+
+		return s.applyPoolProxy(ctx)
+	}
+
+	resp, err := s.applyProxy(ctx, proxy)
+	if err != nil || resp.Error {
+		return resp, err
+	}
+
+	if s.proxy.fromPool {
+		s.proxy.pool.Release(s.sessionId)
+	}
+
+	s.proxy.url = proxy
+	s.proxy.fromPool = false
+
+	return resp, nil
+}
+
+// proxyState returns a copy of the proxy of the session.
+func (s *session) proxyState() sessionProxy {
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	return s.proxy
+}
+
+// keepPoolProxy applies the given proxy of the pool instead of taking the next one, e.g. to keep the proxy of a replaced session.
+func (s *session) keepPoolProxy(ctx context.Context, proxy string) (*SetProxyResponse, error) {
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	if s.proxy.pool == nil {
+		return &SetProxyResponse{}, nil
+	}
+
+	resp, err := s.applyProxy(ctx, proxy)
+	if err != nil || resp.Error {
+		return resp, err
+	}
+
+	if s.proxy.fromPool {
+		s.proxy.pool.Release(s.sessionId)
+	}
+
+	s.proxy.pool.assign(s.sessionId, proxy)
+	s.proxy.url = proxy
+	s.proxy.fromPool = true
+
+	return resp, nil
+}
+
+// requestProxy makes sure a session with a pool has a proxy before the request is sent and returns the proxy used.
+func (s *session) requestProxy(ctx context.Context) (sessionProxy, error) {
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	if s.proxy.pool == nil || s.proxy.url != "" {
+		return s.proxy, nil
+	}
+
+	resp, err := s.applyPoolProxy(ctx)
+	if err != nil {
+		return sessionProxy{}, err
+	}
+
+	if resp.Error {
+		return sessionProxy{}, helheimResponseError(resp.ErrorMsg)
+	}
+
+	return s.proxy, nil
+}
+
+// applyPoolProxy expects the proxy lock to be held.
+func (s *session) applyPoolProxy(ctx context.Context) (*SetProxyResponse, error) {
+	proxy, err := s.proxy.pool.Next(s.sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.applyProxy(ctx, proxy)
+	if err != nil || resp.Error {
+		return resp, err
+	}
+
+	s.proxy.url = proxy
+	s.proxy.fromPool = true
+
+	return resp, nil
+}
+
+func (s *session) applyProxy(ctx context.Context, proxy string) (*SetProxyResponse, error) {
+	if h, ok := s.helheim.(contextHelheim); ok {
+		return h.setProxyContext(ctx, s.GetSessionId(), proxy)
+	}
+
+	return s.helheim.SetProxy(s.GetSessionId(), proxy)
+}
+
+// reportProxyResult feeds the outcome of a request into the pool. Errors not caused by the proxy are not reported.
+// A failed proxy taken from the pool is replaced with the next request.
+func (s *session) reportProxyResult(used sessionProxy, resp *RequestResponse, err error) {
+	if used.pool == nil || used.url == "" {
+		return
+	}
+
+	if err == nil && resp.Error {
+		err = helheimResponseError(resp.ErrorMsg)
+	}
+
+	if err != nil && !errors.Is(err, ErrProxyFailed) {
+		return
+	}
+
+	if err == nil && !used.isBlockStatus(resp.Response.StatusCode) {
+		used.pool.ReportSuccess(used.url)
+		return
+	}
+
+	used.pool.ReportFailure(used.url)
+
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	if !s.proxy.fromPool || s.proxy.url != used.url {
+		return
+	}
+
+	s.proxy.pool.Release(s.sessionId)
+	s.proxy.url = ""
+	s.proxy.fromPool = false
+
+	logFields(s.logger, LevelInfo, "rotating proxy of session after failure",
+		F(FieldOperation, "proxy_rotation"),
+		F(FieldSessionId, s.sessionId),
+	)
+}
+
+func (s *session) releaseProxy() {
+	s.proxyLck.Lock()
+	defer s.proxyLck.Unlock()
+
+	if s.proxy.fromPool {
+		s.proxy.pool.Release(s.sessionId)
+	}
+}
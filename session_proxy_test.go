@@ -0,0 +1,137 @@
+package helheim_go
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHelheimResponseErrorClassifiesProxyFailures(t *testing.T) {
+	tests := []struct {
+		msg   string
+		proxy bool
+	}{
+		{msg: "HTTPSConnectionPool(host='example.com', port=443): Max retries exceeded (Caused by ProxyError('Cannot connect to proxy.'))", proxy: true},
+		{msg: "Tunnel connection failed: 407 Proxy Authentication Required", proxy: true},
+		{msg: "SOCKSHTTPSConnectionPool(host='example.com', port=443): Max retries exceeded", proxy: true},
+		{msg: "HTTPSConnectionPool(host='example.com', port=443): Read timed out. (read timeout=30)", proxy: false},
+		{msg: "Invalid URL 'proxy': No schema supplied", proxy: false},
+	}
+
+	for _, test := range tests {
+		err := helheimResponseError(test.msg)
+
+		if !errors.Is(err, ErrHelheimResponse) {
+			t.Errorf("expected %q to be a helheim response error", test.msg)
+		}
+
+		if errors.Is(err, ErrProxyFailed) != test.proxy {
+			t.Errorf("expected proxy failure %t for %q", test.proxy, test.msg)
+		}
+	}
+}
+
+func newTestProxyPool(t *testing.T) *ProxyPool {
+	t.Helper()
+
+	pool, err := NewProxyPool([]string{"127.0.0.1:8001", "127.0.0.1:8002"}, WithProxyStrategy(ProxyStrategySticky))
+	if err != nil {
+		t.Fatalf("failed to create proxy pool: %v", err)
+	}
+
+	return pool
+}
+
+func proxyStats(pool *ProxyPool, proxy string) ProxyStats {
+	for _, stats := range pool.Stats() {
+		if stats.Url == proxy {
+			return stats
+		}
+	}
+
+	return ProxyStats{}
+}
+
+func TestSessionProxyPoolRotatesOnlyOnProxyFailures(t *testing.T) {
+	backend := newFakeHelheim()
+	pool := newTestProxyPool(t)
+
+	c, err := NewClientWithOptions("api-key", WithBackend(backend), WithSessionProxyPool(pool))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	s, err := c.NewSession(CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	request := func() {
+		_, _ = s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+	}
+
+	request()
+
+	first := s.GetProxy()
+	if first == "" || backend.proxy(s.GetSessionId()) != first {
+		t.Fatalf("expected the session to get a proxy from the pool, got %q", first)
+	}
+
+	request()
+
+	if s.GetProxy() != first || proxyStats(pool, first).Successes != 2 {
+		t.Fatalf("expected the proxy to stick to the session, got %q with %+v", s.GetProxy(), proxyStats(pool, first))
+	}
+
+	backend.onRequest = func(sessionId int, options RequestOptions) (*RequestResponse, error) {
+		return nil, helheimResponseError("Read timed out.")
+	}
+
+	request()
+
+	if stats := proxyStats(pool, first); stats.Successes != 2 || stats.Failures != 0 || s.GetProxy() != first {
+		t.Fatalf("expected errors not caused by the proxy to be ignored, got %+v", stats)
+	}
+
+	backend.onRequest = func(sessionId int, options RequestOptions) (*RequestResponse, error) {
+		return nil, helheimResponseError("ProxyError('Cannot connect to proxy.')")
+	}
+
+	request()
+
+	if stats := proxyStats(pool, first); stats.Failures != 1 {
+		t.Fatalf("expected the proxy failure to be reported, got %+v", stats)
+	}
+
+	backend.onRequest = nil
+
+	request()
+
+	second := s.GetProxy()
+	if second == "" || second == first || backend.proxy(s.GetSessionId()) != second {
+		t.Fatalf("expected the session to rotate away from %q, got %q", first, second)
+	}
+}
+
+func TestSessionProxyPoolKeepsUserProxy(t *testing.T) {
+	backend := newFakeHelheim()
+	backend.onRequest = func(sessionId int, options RequestOptions) (*RequestResponse, error) {
+		return &RequestResponse{Response: RequestResponseResponse{StatusCode: 403}}, nil
+	}
+
+	httpClient := newTestHttpClient(t, backend, WithProxyUrl("http://127.0.0.1:8001"), WithProxyPool(newTestProxyPool(t)))
+
+	for i := 0; i < 3; i++ {
+		_, err := httpClient.Get("https://example.com")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	if backend.count("set_proxy") != 1 || backend.proxy(1) != "http://127.0.0.1:8001" {
+		t.Fatalf("expected the proxy set by the user to be kept, got %q after %d set_proxy calls", backend.proxy(1), backend.count("set_proxy"))
+	}
+
+	if state := httpClient.GetAppliedState(); state.ProxyUrl != "http://127.0.0.1:8001" {
+		t.Errorf("expected the user proxy to stay applied, got %q", state.ProxyUrl)
+	}
+}
@@ -0,0 +1,322 @@
+package helheim_go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	defaultRecoveryBlockThreshold = 2
+	defaultRecoveryMaxRetries     = 1
+)
+
+type RecoveryOption func(config *recoveryConfig)
+
+type recoveryConfig struct {
+	blockThreshold int
+	maxRetries     int
+	switchProxy    bool
+	onEvent        func(event RecoveryEvent)
+}
+
+// WithRecoveryBlockThreshold sets after how many blocked responses in a row the session gets replaced. Defaults to two.
+func WithRecoveryBlockThreshold(blockedResponses int) RecoveryOption {
+	return func(config *recoveryConfig) {
+		config.blockThreshold = blockedResponses
+	}
+}
+
+// WithRecoveryMaxRetries caps how often a single request is retried on a fresh session. Defaults to one.
+func WithRecoveryMaxRetries(retries int) RecoveryOption {
+	return func(config *recoveryConfig) {
+		config.maxRetries = retries
+	}
+}
+
+// WithRecoveryProxySwitch reports the blocked proxy to the proxy pool and takes the next one. Requires a pool set with
+// WithProxyPool or WithSessionProxyPool. Without it the replacement session keeps the pool proxy of the blocked one.
+func WithRecoveryProxySwitch() RecoveryOption {
+	return func(config *recoveryConfig) {
+		config.switchProxy = true
+	}
+}
+
+// WithRecoveryCallback is invoked after every recovery attempt, successful or not.
+func WithRecoveryCallback(onEvent func(event RecoveryEvent)) RecoveryOption {
+	return func(config *recoveryConfig) {
+		config.onEvent = onEvent
+	}
+}
+
+// WithSessionRecovery replaces the session of the HttpClient with a fresh one created from the same
+// CreateSessionOptions once responses keep being blocked, and retries the blocked request on it.
+func WithSessionRecovery(options ...RecoveryOption) HttpClientOption {
+	return func(config *httpClientConfig) {
+		recovery := &recoveryConfig{
+			blockThreshold: defaultRecoveryBlockThreshold,
+			maxRetries:     defaultRecoveryMaxRetries,
+		}
+
+		for _, opt := range options {
+			opt(recovery)
+		}
+
+		config.recovery = recovery
+	}
+}
+
+type RecoveryEvent struct {
+	OldSessionId int
+	// NewSessionId is zero when the recovery failed.
+	NewSessionId int
+	Url          string
+	Challenge    ChallengeType
+	Attempt      int
+	Err          error
+}
+
+func (c *httpClient) doWithRecovery(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.config.recovery == nil {
+		return c.do(ctx, req, c.currentSession())
+	}
+
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+
+		if err != nil {
+			c.logError(req, "failed to prepare helheim request body", err)
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		session := c.currentSession()
+
+		resp, err := c.do(ctx, req, session)
+		if err != nil {
+			return nil, err
+		}
+
+		challenge := ResponseChallenge(resp)
+
+		if !c.recordBlock(challenge.IsBlocked()) {
+			return resp, nil
+		}
+
+		// giving up starts the count again, otherwise every following request would recover right away
+		if attempt > c.config.recovery.maxRetries {
+			c.resetBlocked()
+			return resp, nil
+		}
+
+		err = c.recoverSession(ctx, req, session, challenge, attempt)
+		if err != nil {
+			c.resetBlocked()
+			return resp, nil
+		}
+	}
+}
+
+func (c *httpClient) resetBlocked() {
+	c.sessionLck.Lock()
+	defer c.sessionLck.Unlock()
+
+	c.blocked = 0
+}
+
+// recordBlock counts blocked responses in a row and reports whether the block threshold got reached.
+func (c *httpClient) recordBlock(blocked bool) bool {
+	c.sessionLck.Lock()
+	defer c.sessionLck.Unlock()
+
+	if !blocked {
+		c.blocked = 0
+		return false
+	}
+
+	c.blocked++
+
+	return c.blocked >= c.config.recovery.blockThreshold
+}
+
+// recoverSession replaces the blocked session only while it is still the current one. A session replaced by a
+// concurrent recovery already is left to that recovery.
+func (c *httpClient) recoverSession(ctx context.Context, req *http.Request, old ContextSession, challenge ChallengeType, attempt int) error {
+	if old != c.currentSession() {
+		return nil
+	}
+
+	event := RecoveryEvent{
+		OldSessionId: old.GetSessionId(),
+		Url:          req.URL.String(),
+		Challenge:    challenge,
+		Attempt:      attempt,
+	}
+
+	s, err := c.createRecoverySession(ctx, old)
+	if err != nil {
+		event.Err = err
+		c.emitRecovery(event)
+
+		return err
+	}
+
+	c.appliedLck.Lock()
+	c.sessionLck.Lock()
+
+	swapped := c.session == old
+	if swapped {
+		c.session = s
+		c.blocked = 0
+		c.applied = HttpClientAppliedState{Debug: s.DebugState()}
+	}
+
+	c.sessionLck.Unlock()
+	c.appliedLck.Unlock()
+
+	if !swapped {
+		_ = s.Delete()
+		return nil
+	}
+
+	// the new session took the next proxy from the pool already, a proxy set by the user is applied again
+	if c.config.recovery.switchProxy {
+		if oldSession, ok := old.(proxyPoolSession); ok {
+			if proxy := oldSession.proxyState(); proxy.pool != nil && proxy.url != "" {
+				proxy.pool.ReportFailure(proxy.url)
+			}
+		}
+	}
+
+	err = old.Delete()
+	if err != nil {
+		logFields(c.logger, LevelWarn, "failed to delete blocked session after recovery", F(FieldOperation, "session_recovery"), F(FieldSessionId, old.GetSessionId()), F(FieldError, err))
+	}
+
+	event.NewSessionId = s.GetSessionId()
+	c.emitRecovery(event)
+
+	return nil
+}
+
+// createRecoverySession creates the replacement session and takes over the headers and cookies of the old one.
+func (c *httpClient) createRecoverySession(ctx context.Context, old ContextSession) (ContextSession, error) {
+	if c.newSession == nil {
+		return nil, fmt.Errorf("http client has no session factory to recover from")
+	}
+
+	s, err := c.newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	c.attachProxyPool(s)
+
+	err = c.prepareRecoverySession(ctx, old, s)
+	if err != nil {
+		_ = s.Delete()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (c *httpClient) prepareRecoverySession(ctx context.Context, old ContextSession, s ContextSession) error {
+	if c.config.debug {
+		_, err := s.DebugContext(ctx, DebugLevelOn)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !c.config.recovery.switchProxy {
+		err := keepRecoveryProxy(ctx, old, s)
+		if err != nil {
+			return err
+		}
+	}
+
+	headersResp, err := s.SetHeadersContext(ctx, old.GetHeaders())
+	if err != nil {
+		return err
+	}
+
+	if headersResp.Error {
+		return helheimResponseError(headersResp.ErrorMsg)
+	}
+
+	for _, cookie := range old.GetCookies() {
+		cookieResp, err := s.SetCookie(cookie)
+		if err != nil {
+			return err
+		}
+
+		if cookieResp.Error {
+			return helheimResponseError(cookieResp.ErrorMsg)
+		}
+	}
+
+	return nil
+}
+
+// keepRecoveryProxy lets the replacement session keep the pool proxy of the blocked one. A pool proxy the blocked session
+// already dropped as failed is not kept, the replacement takes the next one from the pool then.
+func keepRecoveryProxy(ctx context.Context, old ContextSession, s ContextSession) error {
+	oldSession, ok := old.(proxyPoolSession)
+	if !ok {
+		return nil
+	}
+
+	newSession, ok := s.(proxyPoolSession)
+	if !ok {
+		return nil
+	}
+
+	proxy := oldSession.proxyState()
+	if !proxy.fromPool || proxy.url == "" {
+		return nil
+	}
+
+	resp, err := newSession.keepPoolProxy(ctx, proxy.url)
+	if err != nil {
+		return err
+	}
+
+	if resp.Error {
+		return helheimResponseError(resp.ErrorMsg)
+	}
+
+	return nil
+}
+
+func (c *httpClient) emitRecovery(event RecoveryEvent) {
+	fields := []Field{
+		F(FieldOperation, "session_recovery"),
+		F(FieldSessionId, event.OldSessionId),
+		F(FieldUrl, event.Url),
+		F("challenge", event.Challenge),
+		F("attempt", event.Attempt),
+	}
+
+	if event.Err != nil {
+		logFields(c.logger, LevelError, "failed to recover blocked http client session", append(fields, F(FieldError, event.Err))...)
+	} else {
+		logFields(c.logger, LevelInfo, "replaced blocked http client session", append(fields, F("new_session_id", event.NewSessionId))...)
+	}
+
+	if c.config.recovery.onEvent != nil {
+		c.config.recovery.onEvent(event)
+	}
+}
@@ -0,0 +1,268 @@
+package helheim_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// fakeSessionFactory creates sessions on the fake backend and counts them.
+type fakeSessionFactory struct {
+	lck      sync.Mutex
+	backend  *fakeHelheim
+	created  int
+	err      error
+	onCreate func()
+}
+
+func (f *fakeSessionFactory) newSession() (ContextSession, error) {
+	f.lck.Lock()
+	f.created++
+	err := f.err
+	f.lck.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if f.onCreate != nil {
+		f.onCreate()
+	}
+
+	return newSession(NewNoopLogger(), NewRealClock(), f.backend, CreateSessionOptions{}, nil, nil, nil)
+}
+
+func newBlockedBackend(t *testing.T) *fakeHelheim {
+	body := string(readFixture(t, "challenges/cloudflare_managed.html"))
+
+	backend := newFakeHelheim()
+	backend.onRequest = func(sessionId int, options RequestOptions) (*RequestResponse, error) {
+		return &RequestResponse{
+			SessionAwareResponse: SessionAwareResponse{SessionId: sessionId},
+			Response: RequestResponseResponse{
+				StatusCode: http.StatusForbidden,
+				Headers:    map[string]string{"Server": "cloudflare", "cf-mitigated": "challenge"},
+				Body:       body,
+			},
+		}, nil
+	}
+
+	return backend
+}
+
+func newRecoveryTestClient(t *testing.T, factory *fakeSessionFactory, options ...RecoveryOption) *httpClient {
+	t.Helper()
+
+	first, err := factory.newSession()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	factory.created = 0
+
+	c, err := newHttpClient(NewNoopLogger(), nil, nil, first, factory.newSession, WithSessionRecovery(options...))
+	if err != nil {
+		t.Fatalf("failed to create http client: %v", err)
+	}
+
+	return c.(*httpClient)
+}
+
+func TestSessionRecoveryStopsAtMaxRetries(t *testing.T) {
+	factory := &fakeSessionFactory{backend: newBlockedBackend(t)}
+
+	var events []RecoveryEvent
+
+	c := newRecoveryTestClient(t, factory,
+		WithRecoveryBlockThreshold(1),
+		WithRecoveryMaxRetries(2),
+		WithRecoveryCallback(func(event RecoveryEvent) { events = append(events, event) }),
+	)
+
+	first := c.currentSession()
+
+	_, err := first.SetCookie(SessionCookie{Name: "login", Value: "token", Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("failed to set cookie: %v", err)
+	}
+
+	_, err = first.SetHeaders(map[string]string{"X-Account": "42"})
+	if err != nil {
+		t.Fatalf("failed to set headers: %v", err)
+	}
+
+	resp, err := c.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if ResponseChallenge(resp) != ChallengeCloudflareManaged {
+		t.Errorf("expected the last blocked response, got %q", ResponseChallenge(resp))
+	}
+
+	if factory.created != 2 || factory.backend.count("request") != 3 {
+		t.Fatalf("expected 2 recoveries and 3 attempts, got %d and %d", factory.created, factory.backend.count("request"))
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 recovery events, got %d", len(events))
+	}
+
+	for i, event := range events {
+		if event.Attempt != i+1 || event.Err != nil || event.Challenge != ChallengeCloudflareManaged || event.NewSessionId != event.OldSessionId+1 {
+			t.Errorf("unexpected recovery event %d: %+v", i, event)
+		}
+	}
+
+	if c.blocked != 0 {
+		t.Errorf("expected the block count to be reset after giving up, got %d", c.blocked)
+	}
+
+	if !first.IsClosed() {
+		t.Error("expected the blocked session to be deleted")
+	}
+
+	current := c.currentSession()
+
+	if cookies := current.GetCookies(); len(cookies) != 1 || cookies[0].Name != "login" {
+		t.Errorf("expected the cookies to be taken over, got %+v", cookies)
+	}
+
+	if current.GetHeaders()["X-Account"] != "42" {
+		t.Errorf("expected the headers to be taken over, got %v", current.GetHeaders())
+	}
+}
+
+func TestSessionRecoveryFailureResetsBlockCount(t *testing.T) {
+	factory := &fakeSessionFactory{backend: newBlockedBackend(t)}
+
+	var events []RecoveryEvent
+
+	c := newRecoveryTestClient(t, factory, WithRecoveryBlockThreshold(1), WithRecoveryCallback(func(event RecoveryEvent) {
+		events = append(events, event)
+	}))
+
+	factory.err = errors.New("no session")
+
+	_, err := c.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if len(events) != 1 || events[0].Err == nil || events[0].NewSessionId != 0 {
+		t.Fatalf("expected one failed recovery event, got %+v", events)
+	}
+
+	if c.blocked != 0 {
+		t.Errorf("expected the block count to be reset, got %d", c.blocked)
+	}
+}
+
+func TestSessionRecoveryKeepsConcurrentlyReplacedSession(t *testing.T) {
+	factory := &fakeSessionFactory{backend: newBlockedBackend(t)}
+	c := newRecoveryTestClient(t, factory)
+
+	old := c.currentSession()
+
+	replacement, err := newSession(NewNoopLogger(), NewRealClock(), factory.backend, CreateSessionOptions{}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	// another request finishes its recovery while this one creates the new session
+	factory.onCreate = func() {
+		c.sessionLck.Lock()
+		c.session = replacement
+		c.sessionLck.Unlock()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	err = c.recoverSession(context.Background(), req, old, ChallengeCloudflareManaged, 1)
+	if err != nil {
+		t.Fatalf("recovery failed: %v", err)
+	}
+
+	if c.currentSession() != replacement {
+		t.Fatalf("expected the concurrently created session to stay current, got session %d", c.currentSession().GetSessionId())
+	}
+
+	if old.IsClosed() || factory.backend.count("delete_session") != 1 {
+		t.Errorf("expected only the superfluous session to be deleted, got %d deletions", factory.backend.count("delete_session"))
+	}
+
+	// a recovery of a session replaced already does not create another one
+	err = c.recoverSession(context.Background(), req, old, ChallengeCloudflareManaged, 1)
+	if err != nil || factory.created != 1 {
+		t.Errorf("expected the stale recovery to be skipped, got %v after %d sessions", err, factory.created)
+	}
+}
+
+func TestSessionRecoveryKeepsPoolProxy(t *testing.T) {
+	factory := &fakeSessionFactory{backend: newBlockedBackend(t)}
+	pool := newTestProxyPool(t)
+
+	first, err := factory.newSession()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	// 403 stays a challenge, not a proxy failure
+	hc, err := newHttpClient(NewNoopLogger(), nil, nil, first, factory.newSession, WithProxyPool(pool, http.StatusTooManyRequests), WithSessionRecovery(WithRecoveryBlockThreshold(1)))
+	if err != nil {
+		t.Fatalf("failed to create http client: %v", err)
+	}
+
+	c := hc.(*httpClient)
+
+	_, err = c.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	current := c.currentSession()
+	if current.GetSessionId() == first.GetSessionId() {
+		t.Fatal("expected the blocked session to be replaced")
+	}
+
+	oldProxy := factory.backend.proxy(first.GetSessionId())
+	if oldProxy == "" || factory.backend.proxy(current.GetSessionId()) != oldProxy {
+		t.Fatalf("expected the replacement to keep proxy %q, got %q", oldProxy, factory.backend.proxy(current.GetSessionId()))
+	}
+
+	if stats := proxyStats(pool, oldProxy); stats.Failures != 0 {
+		t.Errorf("expected the kept proxy not to be reported as failed, got %+v", stats)
+	}
+}
+
+func TestSessionRecoverySwitchesProxyOfClientPool(t *testing.T) {
+	backend := newBlockedBackend(t)
+	pool := newTestProxyPool(t)
+	c := newTestClient(t, backend, WithSessionProxyPool(pool, http.StatusTooManyRequests))
+
+	hc, err := c.NewHttpClient(CreateSessionOptions{}, WithSessionRecovery(WithRecoveryBlockThreshold(1), WithRecoveryProxySwitch()))
+	if err != nil {
+		t.Fatalf("failed to create http client: %v", err)
+	}
+
+	first := hc.(*httpClient).currentSession()
+
+	_, err = hc.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	current := hc.(*httpClient).currentSession()
+	oldProxy := backend.proxy(first.GetSessionId())
+	newProxy := backend.proxy(current.GetSessionId())
+
+	if oldProxy == "" || newProxy == "" || newProxy == oldProxy {
+		t.Fatalf("expected the replacement to switch from proxy %q, got %q", oldProxy, newProxy)
+	}
+
+	if stats := proxyStats(pool, oldProxy); stats.Failures != 1 {
+		t.Errorf("expected the blocked proxy to be reported as failed once, got %+v", stats)
+	}
+}
@@ -0,0 +1,5 @@
+// Package slog_logger adapts a log/slog logger to helheim_go.StructuredLogger.
+//
+// log/slog was added in Go 1.21 while the helheim-go module stays on go 1.17. The adapter is therefore guarded by
+// the go1.21 build tag: built with an older toolchain this package is empty and New is not available.
+package slog_logger
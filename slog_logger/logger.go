@@ -0,0 +1,59 @@
+//go:build go1.21
+
+package slog_logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	helheim_go "github.com/bogdanfinn/helheim-go"
+)
+
+type logger struct {
+	logger *slog.Logger
+}
+
+// New adapts a slog.Logger to helheim_go.StructuredLogger.
+func New(l *slog.Logger) helheim_go.StructuredLogger {
+	return &logger{logger: l}
+}
+
+func (l *logger) Debug(format string, args ...interface{}) {
+	l.logger.Debug(fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Info(format string, args ...interface{}) {
+	l.logger.Info(fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Warn(format string, args ...interface{}) {
+	l.logger.Warn(fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Error(format string, args ...interface{}) {
+	l.logger.Error(fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Log(level helheim_go.Level, msg string, fields ...helheim_go.Field) {
+	attrs := make([]slog.Attr, 0, len(fields))
+
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+
+	l.logger.LogAttrs(context.Background(), toSlogLevel(level), msg, attrs...)
+}
+
+func toSlogLevel(level helheim_go.Level) slog.Level {
+	switch level {
+	case helheim_go.LevelDebug:
+		return slog.LevelDebug
+	case helheim_go.LevelInfo:
+		return slog.LevelInfo
+	case helheim_go.LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
@@ -0,0 +1,45 @@
+//go:build go1.21
+
+package slog_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	helheim_go "github.com/bogdanfinn/helheim-go"
+)
+
+func TestLogMapsLevelsAndFields(t *testing.T) {
+	levels := map[helheim_go.Level]slog.Level{
+		helheim_go.LevelDebug: slog.LevelDebug,
+		helheim_go.LevelInfo:  slog.LevelInfo,
+		helheim_go.LevelWarn:  slog.LevelWarn,
+		helheim_go.LevelError: slog.LevelError,
+		helheim_go.LevelOff:   slog.LevelError,
+		helheim_go.Level(42):  slog.LevelError,
+	}
+
+	for level, expected := range levels {
+		buf := &bytes.Buffer{}
+		l := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		New(l).Log(level, "created new session", helheim_go.F("session_id", 3), helheim_go.F("operation", "create_session"))
+
+		entry := make(map[string]interface{})
+
+		err := json.Unmarshal(buf.Bytes(), &entry)
+		if err != nil {
+			t.Fatalf("%s: expected one json entry, got %q: %v", level, buf.String(), err)
+		}
+
+		if entry[slog.LevelKey] != expected.String() || entry[slog.MessageKey] != "created new session" {
+			t.Fatalf("%s: expected %s entry, got %v", level, expected, entry)
+		}
+
+		if entry["session_id"] != float64(3) || entry["operation"] != "create_session" {
+			t.Fatalf("%s: expected the fields as key values, got %v", level, entry)
+		}
+	}
+}
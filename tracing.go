@@ -0,0 +1,295 @@
+package helheim_go
+
+import (
+	"context"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/bogdanfinn/helheim-go"
+
+var (
+	attributeSessionId  = attribute.Key("helheim.session_id")
+	attributeOperation  = attribute.Key("helheim.operation")
+	attributeHttpMethod = attribute.Key("http.method")
+	attributeHttpHost   = attribute.Key("net.peer.name")
+	attributeHttpStatus = attribute.Key("http.status_code")
+)
+
+// contextHelheim is implemented by backends which take the context of the calling operation into account.
+type contextHelheim interface {
+	requestContext(ctx context.Context, sessionId int, options RequestOptions) (*RequestResponse, error)
+	wokouContext(ctx context.Context, sessionId int, browser string) (*WokouResponse, error)
+	setProxyContext(ctx context.Context, sessionId int, proxy string) (*SetProxyResponse, error)
+	setHeadersContext(ctx context.Context, sessionId int, headers map[string]string) (*SetHeadersResponse, error)
+	debugContext(ctx context.Context, sessionId int, level DebugLevel) (*DebugResponse, error)
+}
+
+// tracingHelheim decorates a Helheim backend and records a span for every operation.
+type tracingHelheim struct {
+	Helheim
+	tracer trace.Tracer
+}
+
+func newTracingHelheim(helheim Helheim, provider trace.TracerProvider) *tracingHelheim {
+	return &tracingHelheim{
+		Helheim: helheim,
+		tracer:  provider.Tracer(tracerName),
+	}
+}
+
+func (t *tracingHelheim) start(ctx context.Context, operation string, sessionId int) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{attributeOperation.String(operation)}
+
+	if sessionId != 0 {
+		attributes = append(attributes, attributeSessionId.Int(sessionId))
+	}
+
+	return t.tracer.Start(ctx, "helheim."+operation, trace.WithAttributes(attributes...))
+}
+
+// startWithAuth starts the span of an operation. As helheim authenticates lazily inside the operation,
+// an authentication due beforehand is run and recorded as child span of the operation.
+func (t *tracingHelheim) startWithAuth(ctx context.Context, operation string, sessionId int) (context.Context, trace.Span, error) {
+	ctx, span := t.start(ctx, operation, sessionId)
+
+	h, ok := t.Helheim.(lazyAuthHelheim)
+	if !ok || !h.needsReAuth() {
+		return ctx, span, nil
+	}
+
+	_, authSpan := t.start(ctx, "auth", 0)
+	err := h.reAuth()
+	endSpan(authSpan, err)
+
+	return ctx, span, err
+}
+
+func (t *tracingHelheim) AuthStatus() AuthStatus {
+	return authStatusOf(t.Helheim)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+func (t *tracingHelheim) Auth() (*AuthResponse, error) {
+	_, span := t.start(context.Background(), "auth", 0)
+	resp, err := t.Helheim.Auth()
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) GetBalance() (*BalanceResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "get_balance", 0)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.GetBalance()
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) CreateSession(options CreateSessionOptions) (*SessionResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "create_session", 0)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.CreateSession(options)
+
+	if err == nil {
+		span.SetAttributes(attributeSessionId.Int(resp.SessionId))
+	}
+
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) DeleteSession(sessionId int) (*SessionDeleteResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "delete_session", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.DeleteSession(sessionId)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) Debug(sessionId int, level DebugLevel) (*DebugResponse, error) {
+	return t.debugContext(context.Background(), sessionId, level)
+}
+
+func (t *tracingHelheim) debugContext(ctx context.Context, sessionId int, level DebugLevel) (*DebugResponse, error) {
+	_, span, err := t.startWithAuth(ctx, "debug", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.Debug(sessionId, level)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) Request(sessionId int, options RequestOptions) (*RequestResponse, error) {
+	return t.requestContext(context.Background(), sessionId, options)
+}
+
+func (t *tracingHelheim) requestContext(ctx context.Context, sessionId int, options RequestOptions) (*RequestResponse, error) {
+	_, span, err := t.startWithAuth(ctx, "request", sessionId)
+	span.SetAttributes(attributeHttpMethod.String(options.Method))
+
+	if u, parseErr := url.Parse(options.Url); parseErr == nil && u.Hostname() != "" {
+		span.SetAttributes(attributeHttpHost.String(u.Hostname()))
+	}
+
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.Request(sessionId, options)
+
+	if err == nil {
+		span.SetAttributes(attributeHttpStatus.Int(resp.Response.StatusCode))
+	}
+
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) Wokou(sessionId int, browser string) (*WokouResponse, error) {
+	return t.wokouContext(context.Background(), sessionId, browser)
+}
+
+func (t *tracingHelheim) wokouContext(ctx context.Context, sessionId int, browser string) (*WokouResponse, error) {
+	_, span, err := t.startWithAuth(ctx, "wokou", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.Wokou(sessionId, browser)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) SetProxy(sessionId int, proxy string) (*SetProxyResponse, error) {
+	return t.setProxyContext(context.Background(), sessionId, proxy)
+}
+
+func (t *tracingHelheim) setProxyContext(ctx context.Context, sessionId int, proxy string) (*SetProxyResponse, error) {
+	_, span, err := t.startWithAuth(ctx, "set_proxy", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.SetProxy(sessionId, proxy)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) SetHeaders(sessionId int, headers map[string]string) (*SetHeadersResponse, error) {
+	return t.setHeadersContext(context.Background(), sessionId, headers)
+}
+
+func (t *tracingHelheim) setHeadersContext(ctx context.Context, sessionId int, headers map[string]string) (*SetHeadersResponse, error) {
+	_, span, err := t.startWithAuth(ctx, "set_headers", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.SetHeaders(sessionId, headers)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) setOrderedHeaders(sessionId int, headers map[string]string, order []string) (*SetHeadersResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "set_headers", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := setOrderedHeaders(t.Helheim, sessionId, headers, order)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) SetCookie(sessionId int, cookie SessionCookie) (*ModifyCookiesResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "set_cookie", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.SetCookie(sessionId, cookie)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) DelCookie(sessionId int, cookieName string) (*ModifyCookiesResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "del_cookie", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.DelCookie(sessionId, cookieName)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) SetKasada(sessionId int, options KasadaOptions) (*SetKasadaResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "set_kasada", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.SetKasada(sessionId, options)
+	endSpan(span, err)
+
+	return resp, err
+}
+
+func (t *tracingHelheim) SetKasadaHooks(sessionId int, options KasadaHooksOptions) (*SetKasadaHooksResponse, error) {
+	_, span, err := t.startWithAuth(context.Background(), "set_kasada_hooks", sessionId)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	resp, err := t.Helheim.SetKasadaHooks(sessionId, options)
+	endSpan(span, err)
+
+	return resp, err
+}
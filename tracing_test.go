@@ -0,0 +1,95 @@
+package helheim_go
+
+import (
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingRecordsOneSpanPerOperation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	backend := newFakeHelheim()
+
+	c, err := NewClientWithOptions("api-key", WithBackend(backend), WithAutoReAuth(), WithTracerProvider(provider))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	s, err := c.NewSession(CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	_, err = s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+	if err != nil {
+		t.Fatalf("failed to request: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+
+	if len(spans) != 2 || names[0] != "helheim.create_session" || names[1] != "helheim.request" {
+		t.Fatalf("expected a create_session and a request span, got %v", names)
+	}
+
+	attributes := make(map[string]interface{})
+	for _, attribute := range spans[1].Attributes {
+		attributes[string(attribute.Key)] = attribute.Value.AsInterface()
+	}
+
+	if attributes["helheim.session_id"] != int64(s.GetSessionId()) || attributes["http.method"] != "GET" || attributes["net.peer.name"] != "example.com" || attributes["http.status_code"] != int64(200) {
+		t.Fatalf("unexpected request span attributes: %v", attributes)
+	}
+
+	if backend.count("auth") != 0 {
+		t.Fatalf("tracing must not authenticate on its own, got %d auth calls", backend.count("auth"))
+	}
+}
+
+func TestTracingRecordsLazyAuthAsChildSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	clock := newFakeClock(time.Unix(1650000000, 0))
+
+	c, err := NewClientWithOptions("api-key", WithAutoReAuth(), WithReAuthInterval(30*time.Minute), WithClock(clock), WithTracerProvider(provider))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = c.GetBalance()
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Name != "helheim.get_balance" {
+		t.Fatalf("expected no auth span while the authentication is valid, got %v", spans)
+	}
+
+	exporter.Reset()
+	clock.advance(31 * time.Minute)
+
+	_, err = c.GetBalance()
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "helheim.auth" || spans[1].Name != "helheim.get_balance" {
+		t.Fatalf("expected an auth and a get_balance span, got %v", spans)
+	}
+
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Fatal("expected the auth span to be a child of the get_balance span")
+	}
+
+	if status := c.AuthStatus(); !status.LastAuthAt.Equal(clock.Now()) {
+		t.Fatalf("expected the authentication at %s, got %s", clock.Now(), status.LastAuthAt)
+	}
+}
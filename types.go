@@ -1,5 +1,10 @@
 package helheim_go
 
+import (
+	"fmt"
+	"time"
+)
+
 type SessionAwareResponse struct {
 	SessionId int `json:"sessionID"`
 }
@@ -14,6 +19,17 @@ type AuthResponse struct {
 	Response string `json:"response"`
 }
 
+type AuthStatus struct {
+	Authenticated       bool
+	LastAuthAt          time.Time
+	LastAttemptAt       time.Time
+	LastError           error
+	ConsecutiveFailures int
+	NextRetryAt         time.Time
+	LicenseExpired      bool
+	LicenseExpiry       time.Time
+}
+
 type BalanceResponse struct {
 	ErrorAwareResponse
 	SessionAwareResponse
@@ -80,25 +96,56 @@ type SetProxyResponse struct {
 	SessionAwareResponse
 }
 
+type SetKasadaResponse struct {
+	ErrorAwareResponse
+	SessionAwareResponse
+}
+
+type SetKasadaHooksResponse struct {
+	ErrorAwareResponse
+	SessionAwareResponse
+}
+
+type DebugResponse struct {
+	ErrorAwareResponse
+	SessionAwareResponse
+	// Raw holds the unparsed payload, which is plain text for helheim versions not answering debug calls with JSON.
+	Raw string `json:"-"`
+}
+
 type WokouResponse struct {
 	ErrorAwareResponse
 	SessionAwareResponse
 	Response string `json:"response"`
 }
 
-type CreateSessionOptions struct {
-	Browser BrowserOptions `json:"browser"`
-	Captcha CaptchaOptions `json:"captcha"`
-}
+// DebugLevel toggles the verbose helheim debug output of a session.
+type DebugLevel int
 
-type BrowserOptions struct {
-	Browser  string `json:"browser"`
-	Mobile   bool   `json:"mobile"`
-	Platform string `json:"platform"`
+const (
+	DebugLevelOff DebugLevel = 0
+	DebugLevelOn  DebugLevel = 1
+)
+
+func (l DebugLevel) String() string {
+	switch l {
+	case DebugLevelOff:
+		return "off"
+	case DebugLevelOn:
+		return "on"
+	default:
+		return fmt.Sprintf("DebugLevel(%d)", int(l))
+	}
 }
 
-type CaptchaOptions struct {
-	Provider string `json:"provider"`
+func (l DebugLevel) validate() error {
+	validationErr := &ValidationError{}
+
+	if l != DebugLevelOff && l != DebugLevelOn {
+		validationErr.add("DebugLevel", "unknown debug level %d", int(l))
+	}
+
+	return validationErr.errOrNil()
 }
 
 type RequestOptions struct {
@@ -113,8 +160,11 @@ type requestOptionsInternal struct {
 	Options map[string]string `json:"options"`
 }
 
-type KasadaOptions struct {
-}
-
-type KasadaHooksOptions struct {
+type SessionInfo struct {
+	SessionId  int
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	UseCount   int
+	Label      string
+	Options    CreateSessionOptions
 }
@@ -0,0 +1,98 @@
+package helheim_go
+
+import (
+	"errors"
+	"time"
+)
+
+const clearanceCookieName = "cf_clearance"
+
+type ErrorClass string
+
+const (
+	ErrorClassHelheim       ErrorClass = "helheim"
+	ErrorClassProxy         ErrorClass = "proxy"
+	ErrorClassSessionClosed ErrorClass = "session_closed"
+	ErrorClassLicense       ErrorClass = "license"
+	ErrorClassAuth          ErrorClass = "auth"
+	ErrorClassValidation    ErrorClass = "validation"
+	ErrorClassOther         ErrorClass = "other"
+)
+
+type Usage struct {
+	Requests         int64
+	ChallengesSolved int64
+	BytesReceived    int64
+	Errors           map[ErrorClass]int64
+}
+
+type SessionUsage struct {
+	SessionId int
+	Label     string
+	Usage
+}
+
+type UsageReport struct {
+	GeneratedAt time.Time
+	Total       Usage
+	ByLabel     map[string]Usage
+	Sessions    []SessionUsage
+	// BalanceStart and BalanceLatest are only set once a balance was retrieved, e.g. by GetBalance or the balance monitor.
+	BalanceStart  *int
+	BalanceLatest *int
+	BalanceDelta  int
+}
+
+func classifyError(err error) ErrorClass {
+	switch {
+	case errors.Is(err, ErrProxyFailed):
+		return ErrorClassProxy
+	case errors.Is(err, ErrHelheimResponse):
+		return ErrorClassHelheim
+	case errors.Is(err, ErrSessionClosed):
+		return ErrorClassSessionClosed
+	case errors.Is(err, ErrLicenseExpired):
+		return ErrorClassLicense
+	case errors.Is(err, ErrAuthBackoff):
+		return ErrorClassAuth
+	case errors.Is(err, ErrInvalidOptions), errors.Is(err, ErrInvalidProxy):
+		return ErrorClassValidation
+	default:
+		return ErrorClassOther
+	}
+}
+
+func (u Usage) copy() Usage {
+	errs := make(map[ErrorClass]int64, len(u.Errors))
+	for class, count := range u.Errors {
+		errs[class] = count
+	}
+
+	u.Errors = errs
+
+	return u
+}
+
+func (u *Usage) add(other Usage) {
+	u.Requests += other.Requests
+	u.ChallengesSolved += other.ChallengesSolved
+	u.BytesReceived += other.BytesReceived
+
+	if u.Errors == nil {
+		u.Errors = make(map[ErrorClass]int64)
+	}
+
+	for class, count := range other.Errors {
+		u.Errors[class] += count
+	}
+}
+
+func clearanceCookie(cookies []SessionCookie) string {
+	for _, cookie := range cookies {
+		if cookie.Name == clearanceCookieName {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,94 @@
+package helheim_go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageReportKeepsClosedSessionsPerLabel(t *testing.T) {
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend)
+
+	solver, _ := c.NewSession(CreateSessionOptions{Label: "a"})
+	other, _ := c.NewSession(CreateSessionOptions{Label: "a"})
+	single, _ := c.NewSession(CreateSessionOptions{Label: "b"})
+
+	// every response of the solver carries the next clearance cookie, an unchanged one is no new solve
+	clearances := []string{"first", "first", "second"}
+	backend.onRequest = func(sessionId int, options RequestOptions) (*RequestResponse, error) {
+		resp := &RequestResponse{
+			SessionAwareResponse: SessionAwareResponse{SessionId: sessionId},
+			Response:             RequestResponseResponse{StatusCode: 200, Body: "ok"},
+		}
+
+		if sessionId == solver.GetSessionId() {
+			resp.Session.Cookies = []SessionCookie{{Name: clearanceCookieName, Value: clearances[0]}}
+			clearances = clearances[1:]
+		}
+
+		return resp, nil
+	}
+
+	for _, s := range []Session{solver, solver, solver, other, single} {
+		_, err := s.Request(RequestOptions{Method: "GET", Url: "https://example.com"})
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+
+	if usage := solver.GetUsage(); usage.ChallengesSolved != 2 {
+		t.Fatalf("expected 2 solved challenges, got %d", usage.ChallengesSolved)
+	}
+
+	err := solver.Delete()
+	if err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+
+	report := c.UsageReport()
+
+	if len(report.Sessions) != 2 {
+		t.Fatalf("expected only the 2 open sessions, got %+v", report.Sessions)
+	}
+
+	a := report.ByLabel["a"]
+	if a.Requests != 4 || a.ChallengesSolved != 2 || a.BytesReceived != 8 {
+		t.Fatalf("expected label a to keep the usage of its closed session, got %+v", a)
+	}
+
+	if b := report.ByLabel["b"]; b.Requests != 1 || b.ChallengesSolved != 0 {
+		t.Fatalf("expected one request for label b, got %+v", b)
+	}
+
+	if report.Total.Requests != 5 || report.Total.ChallengesSolved != 2 || report.Total.BytesReceived != 10 {
+		t.Fatalf("expected the total to contain the closed session, got %+v", report.Total)
+	}
+}
+
+func TestUsageReportBalanceDelta(t *testing.T) {
+	backend := newFakeHelheim()
+	c := newTestClient(t, backend)
+
+	if report := c.UsageReport(); report.BalanceStart != nil || report.BalanceLatest != nil {
+		t.Fatalf("expected no balance before it got retrieved, got %+v", report)
+	}
+
+	for _, balance := range []int{100, 95, 93} {
+		backend.setBalance(balance, time.Time{}, false)
+
+		_, err := c.GetBalance()
+		if err != nil {
+			t.Fatalf("failed to get balance: %v", err)
+		}
+	}
+
+	report := c.UsageReport()
+
+	if report.BalanceStart == nil || *report.BalanceStart != 100 || report.BalanceLatest == nil || *report.BalanceLatest != 93 {
+		t.Fatalf("expected the balance to go from 100 to 93, got %v and %v", report.BalanceStart, report.BalanceLatest)
+	}
+
+	if report.BalanceDelta != 7 {
+		t.Fatalf("expected a balance delta of 7, got %d", report.BalanceDelta)
+	}
+}
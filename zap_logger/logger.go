@@ -0,0 +1,50 @@
+package zap_logger
+
+import (
+	helheim_go "github.com/bogdanfinn/helheim-go"
+	"go.uber.org/zap"
+)
+
+type logger struct {
+	logger *zap.Logger
+}
+
+// New adapts a zap.Logger to helheim_go.StructuredLogger.
+func New(l *zap.Logger) helheim_go.StructuredLogger {
+	return &logger{logger: l}
+}
+
+func (l *logger) Debug(format string, args ...interface{}) {
+	l.logger.Sugar().Debugf(format, args...)
+}
+
+func (l *logger) Info(format string, args ...interface{}) {
+	l.logger.Sugar().Infof(format, args...)
+}
+
+func (l *logger) Warn(format string, args ...interface{}) {
+	l.logger.Sugar().Warnf(format, args...)
+}
+
+func (l *logger) Error(format string, args ...interface{}) {
+	l.logger.Sugar().Errorf(format, args...)
+}
+
+func (l *logger) Log(level helheim_go.Level, msg string, fields ...helheim_go.Field) {
+	zapFields := make([]zap.Field, 0, len(fields))
+
+	for _, field := range fields {
+		zapFields = append(zapFields, zap.Any(field.Key, field.Value))
+	}
+
+	switch level {
+	case helheim_go.LevelDebug:
+		l.logger.Debug(msg, zapFields...)
+	case helheim_go.LevelInfo:
+		l.logger.Info(msg, zapFields...)
+	case helheim_go.LevelWarn:
+		l.logger.Warn(msg, zapFields...)
+	default:
+		l.logger.Error(msg, zapFields...)
+	}
+}
@@ -0,0 +1,41 @@
+package zap_logger
+
+import (
+	"testing"
+
+	helheim_go "github.com/bogdanfinn/helheim-go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogMapsLevelsAndFields(t *testing.T) {
+	levels := map[helheim_go.Level]zapcore.Level{
+		helheim_go.LevelDebug: zapcore.DebugLevel,
+		helheim_go.LevelInfo:  zapcore.InfoLevel,
+		helheim_go.LevelWarn:  zapcore.WarnLevel,
+		helheim_go.LevelError: zapcore.ErrorLevel,
+		helheim_go.LevelOff:   zapcore.ErrorLevel,
+		helheim_go.Level(42):  zapcore.ErrorLevel,
+	}
+
+	for level, expected := range levels {
+		core, logs := observer.New(zapcore.DebugLevel)
+
+		New(zap.New(core)).Log(level, "created new session", helheim_go.F("session_id", 3), helheim_go.F("operation", "create_session"))
+
+		entries := logs.AllUntimed()
+		if len(entries) != 1 {
+			t.Fatalf("%s: expected one entry, got %d", level, len(entries))
+		}
+
+		if entries[0].Level != expected || entries[0].Message != "created new session" {
+			t.Fatalf("%s: expected %s entry, got %s %q", level, expected, entries[0].Level, entries[0].Message)
+		}
+
+		fields := entries[0].ContextMap()
+		if fields["session_id"] != int64(3) || fields["operation"] != "create_session" {
+			t.Fatalf("%s: expected the fields as key values, got %v", level, fields)
+		}
+	}
+}